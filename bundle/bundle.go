@@ -0,0 +1,226 @@
+// Package bundle write and read the class bundles, a zip or a tar inside a
+// zstd frame. The format of a bundle is found from its content.
+package bundle
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+)
+
+var (
+	ErrFormat  = errors.New("bundle format must be zip or tar.zst")
+	ErrUnknown = errors.New("not a zip or tar.zst bundle")
+
+	magicZip  = []byte("PK\x03\x04")
+	magicZstd = []byte{0x28, 0xB5, 0x2F, 0xFD}
+)
+
+// storedExt are the files already compressed, they are stored as is in the
+// zip.
+var storedExt = []string{".mp4", ".m4a", ".jpg", ".jpeg", ".png", ".zip"}
+
+type Entry struct {
+	Name    string
+	Size    int64
+	ModTime time.Time
+	Mode    fs.FileMode
+}
+
+// Writer add the files to a bundle, a file must be written before the next
+// one is created.
+type Writer interface {
+	Create(name string, size int64, modTime time.Time) (io.Writer, error)
+	Close() error
+}
+
+// Reader returns the files of a bundle in the order they are written.
+type Reader interface {
+	// Next returns the next entry and its content, io.EOF after the last
+	// entry.
+	Next() (Entry, io.Reader, error)
+	Close() error
+}
+
+func NewWriter(w io.Writer, format string) (Writer, error) {
+	switch format {
+	case constants.BundleZip:
+		return &zipWriter{zw: zip.NewWriter(w)}, nil
+	case constants.BundleTarZst:
+		zw, err := zstd.NewWriter(w)
+		if err != nil {
+			return nil, err
+		}
+		return &tarWriter{zw: zw, tw: tar.NewWriter(zw)}, nil
+	}
+	return nil, ErrFormat
+}
+
+// Open the bundle, the format is found from the first bytes of the file.
+func Open(filePath string) (Reader, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(file, magic); err != nil {
+		file.Close()
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ErrUnknown
+		}
+		return nil, err
+	}
+
+	switch {
+	case bytes.Equal(magic, magicZip):
+		file.Close()
+		zr, err := zip.OpenReader(filePath)
+		if err != nil {
+			return nil, err
+		}
+		return &zipReader{zr: zr}, nil
+	case bytes.Equal(magic, magicZstd):
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			file.Close()
+			return nil, err
+		}
+		zr, err := zstd.NewReader(file)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		return &tarReader{file: file, zr: zr, tr: tar.NewReader(zr)}, nil
+	}
+
+	file.Close()
+	return nil, ErrUnknown
+}
+
+type zipWriter struct {
+	zw *zip.Writer
+}
+
+func (w *zipWriter) Create(name string, size int64, modTime time.Time) (io.Writer, error) {
+	method := zip.Deflate
+	for _, ext := range storedExt {
+		if strings.EqualFold(path.Ext(name), ext) {
+			method = zip.Store
+		}
+	}
+
+	return w.zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   method,
+		Modified: modTime,
+	})
+}
+
+func (w *zipWriter) Close() error {
+	return w.zw.Close()
+}
+
+type tarWriter struct {
+	zw *zstd.Encoder
+	tw *tar.Writer
+}
+
+func (w *tarWriter) Create(name string, size int64, modTime time.Time) (io.Writer, error) {
+	err := w.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     0o644,
+		ModTime:  modTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return w.tw, nil
+}
+
+func (w *tarWriter) Close() error {
+	if err := w.tw.Close(); err != nil {
+		return err
+	}
+	return w.zw.Close()
+}
+
+type zipReader struct {
+	zr      *zip.ReadCloser
+	idx     int
+	current io.ReadCloser
+}
+
+func (r *zipReader) Next() (Entry, io.Reader, error) {
+	if r.current != nil {
+		r.current.Close()
+		r.current = nil
+	}
+
+	if r.idx >= len(r.zr.File) {
+		return Entry{}, nil, io.EOF
+	}
+
+	file := r.zr.File[r.idx]
+	r.idx++
+
+	entry := Entry{
+		Name:    file.Name,
+		Size:    int64(file.UncompressedSize64),
+		ModTime: file.Modified,
+		Mode:    file.Mode(),
+	}
+	if !entry.Mode.IsRegular() {
+		return entry, bytes.NewReader(nil), nil
+	}
+
+	current, err := file.Open()
+	if err != nil {
+		return entry, nil, err
+	}
+	r.current = current
+	return entry, current, nil
+}
+
+func (r *zipReader) Close() error {
+	if r.current != nil {
+		r.current.Close()
+	}
+	return r.zr.Close()
+}
+
+type tarReader struct {
+	file *os.File
+	zr   *zstd.Decoder
+	tr   *tar.Reader
+}
+
+func (r *tarReader) Next() (Entry, io.Reader, error) {
+	header, err := r.tr.Next()
+	if err != nil {
+		return Entry{}, nil, err
+	}
+
+	return Entry{
+		Name:    header.Name,
+		Size:    header.Size,
+		ModTime: header.ModTime,
+		Mode:    header.FileInfo().Mode(),
+	}, r.tr, nil
+}
+
+func (r *tarReader) Close() error {
+	r.zr.Close()
+	return r.file.Close()
+}
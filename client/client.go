@@ -0,0 +1,178 @@
+// Package client is a Skillshare and Brightcove client without any logging
+// or terminal output, it can be embedded by other Go programs.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"golang.org/x/time/rate"
+)
+
+// Endpoints are the urls of the apis, Class and Video are formatted with the
+// ids like the constants of the same name.
+type Endpoints struct {
+	Class               string
+	Video               string
+	Me                  string
+	BrightcoveAccountID int64
+	PolicyKey           string
+}
+
+func DefaultEndpoints() Endpoints {
+	return Endpoints{
+		Class:               constants.APIClass,
+		Video:               constants.APIVideo,
+		Me:                  constants.APIMe,
+		BrightcoveAccountID: constants.BrightcoveAccountId,
+		PolicyKey:           constants.PolicyKey,
+	}
+}
+
+type Option func(*Client)
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.http = httpClient
+	}
+}
+
+func WithCookies(cookies string) Option {
+	return func(c *Client) {
+		c.cookies = cookies
+	}
+}
+
+func WithEndpoints(endpoints Endpoints) Option {
+	return func(c *Client) {
+		c.endpoints = endpoints
+	}
+}
+
+// WithRateLimit share the bandwidth in bytes per second between every
+// download of the client.
+func WithRateLimit(bytesPerSecond int64) Option {
+	return func(c *Client) {
+		c.limiter = newRateLimiter(bytesPerSecond)
+	}
+}
+
+type Client struct {
+	http      *http.Client
+	cookies   string
+	endpoints Endpoints
+	limiter   *rate.Limiter
+}
+
+func New(opts ...Option) *Client {
+	c := &Client{
+		http:      &http.Client{},
+		endpoints: DefaultEndpoints(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// GetClass returns the class data with the sessions and units, the video id
+// of the sessions are only available for a premium account.
+func (c *Client) GetClass(ctx context.Context, id int) (*models.ClassData, error) {
+	dest := &models.ClassData{}
+	err := c.getJson(ctx, fmt.Sprintf(c.endpoints.Class, id), c.skillshareHeader("class"), true, dest)
+	if err != nil {
+		return nil, err
+	}
+	return dest, nil
+}
+
+// GetMe returns ErrUnauthorized when the cookies are not logged in.
+func (c *Client) GetMe(ctx context.Context) (*models.UserData, error) {
+	dest := &models.UserData{}
+	err := c.getJson(ctx, c.endpoints.Me, c.skillshareHeader("user"), true, dest)
+	if err != nil {
+		return nil, err
+	}
+
+	if dest.ID == 0 {
+		return nil, ErrUnauthorized
+	}
+	return dest, nil
+}
+
+// GetPlayback returns the sources and text tracks of the Brightcove video.
+func (c *Client) GetPlayback(ctx context.Context, videoID int) (*models.VideoData, error) {
+	header := http.Header{
+		"Accept":     {fmt.Sprintf("application/json;pk=%s", c.endpoints.PolicyKey)},
+		"User-Agent": {"Mozilla/5.0 (X11; Linux x86_64; rv:52.0) Gecko/20100101 Firefox/52.0"},
+		"Origin":     {"https://www.skillshare.com/"},
+	}
+
+	dest := &models.VideoData{}
+	err := c.getJson(ctx, fmt.Sprintf(c.endpoints.Video, c.endpoints.BrightcoveAccountID, videoID), header, false, dest)
+	if err != nil {
+		return nil, err
+	}
+	return dest, nil
+}
+
+// GetSubtitle returns the content of the subtitle file.
+func (c *Client) GetSubtitle(ctx context.Context, sub models.SkillshareVideoSubtitle) ([]byte, error) {
+	header := c.skillshareHeader("class")
+	header.Del("Cookie")
+
+	resp, err := c.get(ctx, sub.Src, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkStatus(resp, false); err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func (c *Client) skillshareHeader(kind string) http.Header {
+	return http.Header{
+		"Accept":     {fmt.Sprintf("application/vnd.skillshare.%s+json;,version=0.8", kind)},
+		"User-Agent": {"Skillshare/5.3.0; Android 9.0.1"},
+		"Referer":    {"https://www.skillshare.com/"},
+		"Cookie":     {c.cookies},
+	}
+}
+
+func (c *Client) get(ctx context.Context, url string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header = header
+	return c.http.Do(req)
+}
+
+func (c *Client) getJson(ctx context.Context, url string, header http.Header, isAuth bool, dest any) error {
+	resp, err := c.get(ctx, url, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := checkStatus(resp, isAuth); err != nil {
+		return err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, dest)
+}
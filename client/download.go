@@ -0,0 +1,168 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/melbahja/got"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/mp4"
+)
+
+// Progress of a download, Speed is in bytes per second.
+type Progress struct {
+	Bytes      int64
+	TotalBytes int64
+	Speed      int64
+}
+
+type DownloadOptions struct {
+	// Quality is the maximum height of the video, 0 is the best one.
+	Quality int
+	// Concurrency is the number of connections, default to the number of cpu.
+	Concurrency uint
+	// Progress is called periodically while downloading.
+	Progress func(Progress)
+	// Refresh returns a new url of the source when the signed url expired,
+	// default to fetch the playback again.
+	Refresh func() (string, error)
+	// AudioOnly download the smallest source and keep only its audio track
+	// as a m4a with the Tags, the video is not kept.
+	AudioOnly bool
+	Tags      mp4.Tags
+}
+
+type DownloadResult struct {
+	Path   string
+	Source models.SkillshareVideoSource
+	Size   int64
+}
+
+// audioSuffix is the video downloaded before the audio is extracted, it
+// starts with .part like the other leftovers of an interrupted download.
+const audioSuffix = ".part.mp4"
+
+// DownloadLesson download the video of the lesson to dst, the playback is
+// fetched when the lesson has no sources.
+func (c *Client) DownloadLesson(ctx context.Context, lesson models.SkillshareVideo, dst string, opts DownloadOptions) (*DownloadResult, error) {
+	if len(lesson.Sources) == 0 {
+		video, err := c.GetPlayback(ctx, lesson.ID)
+		if err != nil {
+			return nil, err
+		}
+		lesson.AddSourceSubtitle(*video)
+	}
+
+	source, ok := lesson.SelectSource(opts.Quality)
+	if opts.AudioOnly {
+		source, ok = lesson.SelectAudioSource()
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrNoSource, lesson.ID)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	refresh := opts.Refresh
+	if refresh == nil {
+		refresh = c.refreshSource(ctx, lesson, source.Height, opts.Quality)
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency == 0 {
+		concurrency = uint(runtime.NumCPU())
+	}
+
+	base := c.http.Transport
+	if base == nil {
+		base = got.DefaultClient.Transport
+	}
+
+	dl := got.NewWithContext(ctx)
+	dl.Client = &http.Client{
+		Transport: newRefreshTransport(newLimitTransport(base, c.limiter), source.Src, refresh),
+	}
+	dl.ProgressFunc = func(download *got.Download) {
+		download.Concurrency = concurrency
+		if opts.Progress != nil {
+			opts.Progress(Progress{
+				Bytes:      int64(download.Size()),
+				TotalBytes: int64(download.TotalSize()),
+				Speed:      int64(download.Speed()),
+			})
+		}
+	}
+
+	target := dst
+	if opts.AudioOnly {
+		target = dst + audioSuffix
+	}
+
+	if err := dl.Download(source.Src, target); err != nil {
+		return nil, err
+	}
+
+	if opts.AudioOnly {
+		err := mp4.ExtractAudioFile(target, dst, opts.Tags)
+		os.Remove(target)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	info, err := os.Stat(dst)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DownloadResult{
+		Path:   dst,
+		Source: source,
+		Size:   info.Size(),
+	}, nil
+}
+
+// DownloadSubtitle write the subtitle to dst.
+func (c *Client) DownloadSubtitle(ctx context.Context, sub models.SkillshareVideoSubtitle, dst string) error {
+	data, err := c.GetSubtitle(ctx, sub)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+
+	return os.WriteFile(dst, data, 0o644)
+}
+
+// refreshSource fetch the playback again and returns the source with the
+// same height.
+func (c *Client) refreshSource(ctx context.Context, lesson models.SkillshareVideo, height, quality int) func() (string, error) {
+	return func() (string, error) {
+		video, err := c.GetPlayback(ctx, lesson.ID)
+		if err != nil {
+			return "", err
+		}
+
+		fresh := lesson
+		fresh.AddSourceSubtitle(*video)
+		for _, source := range fresh.Sources {
+			if source.Height == height {
+				return source.Src, nil
+			}
+		}
+
+		source, ok := fresh.SelectSource(quality)
+		if !ok {
+			return "", fmt.Errorf("%w after refresh: %d", ErrNoSource, lesson.ID)
+		}
+		return source.Src, nil
+	}
+}
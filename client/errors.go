@@ -0,0 +1,61 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	ErrUnauthorized = errors.New("invalid Skillshare cookies")
+	ErrRateLimited  = errors.New("Skillshare rate limit exceeded")
+	ErrNotFound     = errors.New("Skillshare resource not found")
+	ErrNoSource     = errors.New("lesson has no video source")
+)
+
+// StatusError is returned for an unexpected status code, errors.Is match it
+// with ErrUnauthorized, ErrRateLimited and ErrNotFound.
+type StatusError struct {
+	URL        string
+	StatusCode int
+	err        error
+}
+
+func (e *StatusError) Error() string {
+	if e.err != nil {
+		return fmt.Sprintf("%s: %s has status code %d", e.err.Error(), e.URL, e.StatusCode)
+	}
+	return fmt.Sprintf("%s has status code %d", e.URL, e.StatusCode)
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.err
+}
+
+// checkStatus returns nil for a 2xx response, Skillshare returns 500 for a
+// request with invalid cookies so it is unauthorized when isAuth is true.
+func checkStatus(resp *http.Response, isAuth bool) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	statusErr := &StatusError{
+		URL:        resp.Request.URL.Redacted(),
+		StatusCode: resp.StatusCode,
+	}
+
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		statusErr.err = ErrNotFound
+	case http.StatusTooManyRequests:
+		statusErr.err = ErrRateLimited
+	case http.StatusUnauthorized, http.StatusForbidden:
+		statusErr.err = ErrUnauthorized
+	case http.StatusInternalServerError:
+		if isAuth {
+			statusErr.err = ErrUnauthorized
+		}
+	}
+
+	return statusErr
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"golang.org/x/time/rate"
+)
+
+// limitTransport share one bandwidth limit between every connection of the
+// downloads, the limit is applied while reading the response body.
+type limitTransport struct {
+	base    http.RoundTripper
+	limiter *rate.Limiter
+}
+
+func newRateLimiter(bytesPerSecond int64) *rate.Limiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+
+	return rate.NewLimiter(rate.Limit(bytesPerSecond), int(bytesPerSecond))
+}
+
+func newLimitTransport(base http.RoundTripper, limiter *rate.Limiter) http.RoundTripper {
+	if limiter == nil {
+		return base
+	}
+
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &limitTransport{
+		base:    base,
+		limiter: limiter,
+	}
+}
+
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	resp.Body = &limitReader{
+		ctx:     req.Context(),
+		body:    resp.Body,
+		limiter: t.limiter,
+	}
+	return resp, nil
+}
+
+type limitReader struct {
+	ctx     context.Context
+	body    io.ReadCloser
+	limiter *rate.Limiter
+}
+
+func (r *limitReader) Read(p []byte) (int, error) {
+	if burst := r.limiter.Burst(); len(p) > burst {
+		p = p[:burst]
+	}
+
+	n, err := r.body.Read(p)
+	if n > 0 {
+		if errWait := r.limiter.WaitN(r.ctx, n); errWait != nil {
+			return n, errWait
+		}
+	}
+	return n, err
+}
+
+func (r *limitReader) Close() error {
+	return r.body.Close()
+}
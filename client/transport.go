@@ -0,0 +1,104 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/rizalarfiyan/skillshare-downloader/utils"
+)
+
+// refreshTransport renew the signed source url when it is expired or the
+// server returns 403, requests to the old url are sent to the renewed one so
+// the running download continue with the same range.
+type refreshTransport struct {
+	base    http.RoundTripper
+	refresh func() (string, error)
+
+	mu      sync.Mutex
+	current string
+	expired map[string]bool
+}
+
+func newRefreshTransport(base http.RoundTripper, src string, refresh func() (string, error)) *refreshTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &refreshTransport{
+		base:    base,
+		refresh: refresh,
+		current: src,
+		expired: make(map[string]bool),
+	}
+}
+
+func (t *refreshTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	target := t.target(req.URL.String())
+	if utils.IsSignedURLExpired(target, 0) {
+		renewed, err := t.renew(target)
+		if err != nil {
+			return nil, err
+		}
+		target = renewed
+	}
+
+	resp, err := t.do(req, target)
+	if err != nil || resp.StatusCode != http.StatusForbidden {
+		return resp, err
+	}
+
+	resp.Body.Close()
+	renewed, err := t.renew(target)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.do(req, renewed)
+}
+
+func (t *refreshTransport) target(requestURL string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.expired[requestURL] {
+		return t.current
+	}
+	return requestURL
+}
+
+// renew only call refresh once for concurrent chunks failing with same url.
+func (t *refreshTransport) renew(failed string) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if failed != t.current && t.expired[failed] {
+		return t.current, nil
+	}
+
+	renewed, err := t.refresh()
+	if err != nil {
+		return "", err
+	}
+
+	t.expired[failed] = true
+	t.expired[t.current] = true
+	t.current = renewed
+	return renewed, nil
+}
+
+func (t *refreshTransport) do(req *http.Request, target string) (*http.Response, error) {
+	if target == req.URL.String() {
+		return t.base.RoundTrip(req)
+	}
+
+	targetURL, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+
+	clone := req.Clone(req.Context())
+	clone.URL = targetURL
+	clone.Host = ""
+	return t.base.RoundTrip(clone)
+}
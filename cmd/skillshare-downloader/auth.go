@@ -0,0 +1,203 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+	"github.com/rizalarfiyan/skillshare-downloader/logger"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/services"
+	"github.com/rizalarfiyan/skillshare-downloader/utils"
+	"github.com/rizalarfiyan/skillshare-downloader/vault"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+)
+
+func authCommand() *cli.Command {
+	accountFlag := &cli.StringFlag{
+		Name:    "account",
+		Aliases: []string{"a"},
+		EnvVars: []string{"SKILLSHARE_VAULT"},
+		Usage:   "Account name in the vault",
+		Value:   constants.DefaultVaultAccount,
+	}
+
+	vaultFileFlag := &cli.StringFlag{
+		Name:        "vault-file",
+		EnvVars:     []string{"SKILLSHARE_VAULT_FILE"},
+		Usage:       "Encrypted cookie vault file",
+		DefaultText: vault.DefaultPath(),
+	}
+
+	return &cli.Command{
+		Name:  "auth",
+		Usage: "Manage the skillshare cookies",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "store",
+				Usage:     "Save cookies into the encrypted vault, read from --cookies, --cookie-file or stdin",
+				UsageText: "skillshare-dl auth store --account <name> --cookie-file <cookie-path>",
+				Flags: []cli.Flag{
+					accountFlag,
+					vaultFileFlag,
+					&cli.StringFlag{
+						Name:    "cookies",
+						Aliases: []string{"co"},
+						Usage:   "String cookies for get content to skillshare",
+					},
+					&cli.StringFlag{
+						Name:    "cookie-file",
+						Aliases: []string{"cf"},
+						Usage:   "Cookie File (.txt) for get content to skillshare",
+					},
+				},
+				Action: func(cliCtx *cli.Context) error {
+					cookies, err := readStoreCookies(cliCtx)
+					if err != nil {
+						return err
+					}
+
+					v, err := vault.Open(cliCtx.String("vault-file"))
+					if err != nil {
+						return err
+					}
+
+					passphrase, err := vault.Passphrase(true)
+					if err != nil {
+						return err
+					}
+
+					account := cliCtx.String("account")
+					err = v.Store(account, passphrase, cookies)
+					if err != nil {
+						return err
+					}
+
+					logger.Infof("Cookies for account %s stored in %s", account, v.Path())
+					return nil
+				},
+			},
+			{
+				Name:  "check",
+				Usage: "Check the cookies are logged in with premium account, exit non-zero when unusable",
+				Flags: optionFlags(),
+				Action: func(cliCtx *cli.Context) error {
+					setupLogger(cliCtx)
+
+					conf, err := resolveConfig(cliCtx)
+					if err != nil {
+						return err
+					}
+
+					status, err := services.NewAuth(cliCtx.Context).Check(conf)
+					if err != nil {
+						return err
+					}
+
+					printAuthStatus(status)
+					if !status.IsUsable() {
+						return cli.Exit("Skillshare cookies is unusable", 1)
+					}
+					return nil
+				},
+			},
+			{
+				Name:  "list",
+				Usage: "List the accounts in the vault",
+				Flags: []cli.Flag{vaultFileFlag},
+				Action: func(cliCtx *cli.Context) error {
+					v, err := vault.Open(cliCtx.String("vault-file"))
+					if err != nil {
+						return err
+					}
+
+					for _, account := range v.Accounts() {
+						fmt.Println(account)
+					}
+					return nil
+				},
+			},
+			{
+				Name:  "remove",
+				Usage: "Remove an account from the vault",
+				Flags: []cli.Flag{accountFlag, vaultFileFlag},
+				Action: func(cliCtx *cli.Context) error {
+					v, err := vault.Open(cliCtx.String("vault-file"))
+					if err != nil {
+						return err
+					}
+
+					account := cliCtx.String("account")
+					err = v.Remove(account)
+					if err != nil {
+						return err
+					}
+
+					logger.Infof("Account %s removed from %s", account, v.Path())
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func printAuthStatus(status *models.AuthStatus) {
+	yesNo := func(value bool) string {
+		if value {
+			return "yes"
+		}
+		return "no"
+	}
+
+	fmt.Printf("Logged in : %s\n", yesNo(status.IsLoggedIn))
+	fmt.Printf("Premium   : %s\n", yesNo(status.IsPremium))
+	if status.IsLoggedIn {
+		fmt.Printf("Account   : %s (%d)\n", status.Account, status.UserID)
+	}
+
+	switch {
+	case status.CookieExpiry.IsZero():
+		fmt.Println("Expires   : unknown (use netscape cookies.txt to see the expiry)")
+	case status.IsExpired():
+		fmt.Printf("Expires   : expired at %s\n", status.CookieExpiry.Format(constants.DefaultTimestampFormat))
+	default:
+		fmt.Printf("Expires   : %s (in %s)\n", status.CookieExpiry.Format(constants.DefaultTimestampFormat), time.Until(status.CookieExpiry).Round(time.Minute))
+	}
+}
+
+func readStoreCookies(cliCtx *cli.Context) (string, error) {
+	cookies := cliCtx.String("cookies")
+	if cookieFile := cliCtx.String("cookie-file"); cookies == "" && cookieFile != "" {
+		var err error
+		cookies, err = utils.GetCookieTxt(cookieFile)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if cookies == "" && !term.IsTerminal(int(os.Stdin.Fd())) {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", err
+		}
+		cookies = string(data)
+	}
+
+	// netscape cookies keep the expiry, it is converted when loaded
+	if _, ok := utils.ParseNetscapeCookies(cookies); ok {
+		cookies = strings.TrimSpace(cookies)
+	} else {
+		cookies = utils.CleanCookies(cookies)
+	}
+
+	if cookies == "" {
+		return "", errors.New("cookies, cookie-file or stdin is required")
+	}
+
+	return cookies, nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+	"github.com/rizalarfiyan/skillshare-downloader/logger"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/services"
+	"github.com/urfave/cli/v2"
+)
+
+func exportCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "export",
+		Usage:     "Package a downloaded class with the hashes of its files into a bundle",
+		ArgsUsage: "<class>",
+		Flags: append(optionFlags(),
+			&cli.StringFlag{
+				Name:        "format",
+				Usage:       "Format of the bundle, zip or tar.zst",
+				Value:       constants.DefaultBundleFormat,
+				DefaultText: constants.DefaultBundleFormat,
+			},
+			&cli.StringFlag{
+				Name:        "output",
+				Aliases:     []string{"o"},
+				Usage:       "Path of the bundle",
+				DefaultText: "<class id>_<class title>.<format>",
+			},
+		),
+		Action: func(cliCtx *cli.Context) error {
+			setupLogger(cliCtx)
+
+			if cliCtx.NArg() != 1 {
+				return errors.New("one class is required")
+			}
+
+			id, err := models.ParseClassID(cliCtx.Args().First())
+			if err != nil {
+				return fmt.Errorf("%s: %w", cliCtx.Args().First(), err)
+			}
+
+			conf, err := resolveConfig(cliCtx)
+			if err != nil {
+				return err
+			}
+
+			output, err := services.NewBundle().Export(conf, id, models.ExportOptions{
+				Format: cliCtx.String("format"),
+				Output: cliCtx.String("output"),
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(output)
+			return nil
+		},
+	}
+}
+
+func importCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "import",
+		Usage:     "Verify the hashes of a class bundle and place the class into the download root",
+		ArgsUsage: "<bundle>",
+		Flags: append(optionFlags(), &cli.BoolFlag{
+			Name:  "force",
+			Usage: "Replace the class when it is already in the download root",
+		}),
+		Action: func(cliCtx *cli.Context) error {
+			setupLogger(cliCtx)
+
+			if cliCtx.NArg() != 1 {
+				return errors.New("one bundle is required")
+			}
+
+			conf, err := resolveConfig(cliCtx)
+			if err != nil {
+				return err
+			}
+
+			manifest, err := services.NewBundle().Import(conf, cliCtx.Args().First(), models.ImportOptions{
+				IsForce: cliCtx.Bool("force"),
+			})
+			if err != nil {
+				return err
+			}
+
+			logger.Infof("[%d] Import %s with %d files done", manifest.ClassID, manifest.Title, len(manifest.Files))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/utils"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+func configCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "config",
+		Usage: "Inspect the config file and profiles",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "show",
+				Usage: "Print the effective config after merging flags, profile and defaults",
+				Flags: optionFlags(),
+				Action: func(cliCtx *cli.Context) error {
+					conf, err := resolveConfig(cliCtx)
+					if err != nil {
+						return err
+					}
+
+					profile := models.ProfileFromConfig(conf)
+					profile.Cookies = utils.RedactCookies(profile.Cookies)
+					value, err := yaml.Marshal(profile)
+					if err != nil {
+						return err
+					}
+
+					fmt.Print(string(value))
+					return nil
+				},
+			},
+		},
+	}
+}
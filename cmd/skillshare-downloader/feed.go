@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+	"github.com/rizalarfiyan/skillshare-downloader/logger"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/podcast"
+	"github.com/rizalarfiyan/skillshare-downloader/services"
+	"github.com/rizalarfiyan/skillshare-downloader/utils"
+	"github.com/urfave/cli/v2"
+)
+
+func feedCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "feed",
+		Usage:     "Write a podcast feed of the downloaded classes, optionally served on the local network",
+		ArgsUsage: "[class...]",
+		Flags: append(optionFlags(),
+			&cli.BoolFlag{
+				Name:  "library",
+				Usage: "Write one feed of every class in the download root instead of one feed per class",
+			},
+			&cli.StringFlag{
+				Name:        "base-url",
+				EnvVars:     []string{"SKILLSHARE_FEED_BASE_URL"},
+				Usage:       "Url of the download root used in the feed",
+				DefaultText: "http://<local ip><listen port>",
+			},
+			&cli.BoolFlag{
+				Name:  "serve",
+				Usage: "Serve the feeds and the media after writing them",
+			},
+			&cli.StringFlag{
+				Name:    "listen",
+				EnvVars: []string{"SKILLSHARE_FEED_LISTEN"},
+				Usage:   "Address of the feed server",
+				Value:   constants.DefaultFeedListen,
+			},
+		),
+		Action: func(cliCtx *cli.Context) error {
+			setupLogger(cliCtx)
+
+			conf, err := resolveConfig(cliCtx)
+			if err != nil {
+				return err
+			}
+
+			if !utils.IsExistPath(conf.Dir) {
+				return fmt.Errorf("directory %s not found", conf.Dir)
+			}
+
+			var ids []int
+			for _, arg := range cliCtx.Args().Slice() {
+				id, err := models.ParseClassID(arg)
+				if err != nil {
+					return fmt.Errorf("%s: %w", arg, err)
+				}
+				ids = append(ids, id)
+			}
+
+			baseURL := cliCtx.String("base-url")
+			if baseURL == "" {
+				baseURL, err = localURL(cliCtx.String("listen"))
+				if err != nil {
+					return err
+				}
+			}
+
+			paths, err := services.NewFeed().Run(conf, ids, models.FeedOptions{
+				BaseURL:   baseURL,
+				IsLibrary: cliCtx.Bool("library"),
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, filePath := range paths {
+				rel, err := filepath.Rel(conf.Dir, filePath)
+				if err != nil {
+					rel = filePath
+				}
+				fmt.Println(podcast.URL(baseURL, rel))
+			}
+
+			if !cliCtx.Bool("serve") {
+				return nil
+			}
+			return serveFeed(cliCtx.Context, cliCtx.String("listen"), conf.Dir)
+		},
+	}
+}
+
+func serveFeed(ctx context.Context, listen, root string) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	httpServer := &http.Server{
+		Addr:              listen,
+		Handler:           podcast.Handler(root),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
+	logger.Infof("Serve the feeds of %s on %s", root, listen)
+	err := httpServer.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		logger.Info("Server is stopped")
+		return nil
+	}
+	return err
+}
+
+// localURL returns the url of the listen address for the other devices of
+// the network, the first private ipv4 is used when the host is empty.
+func localURL(listen string) (string, error) {
+	host, port, err := net.SplitHostPort(listen)
+	if err != nil {
+		return "", fmt.Errorf("invalid listen %s: %w", listen, err)
+	}
+
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+		addrs, err := net.InterfaceAddrs()
+		if err == nil {
+			for _, addr := range addrs {
+				ipNet, ok := addr.(*net.IPNet)
+				if ok && ipNet.IP.To4() != nil && ipNet.IP.IsPrivate() {
+					host = ipNet.IP.String()
+					break
+				}
+			}
+		}
+	}
+
+	return fmt.Sprintf("http://%s", net.JoinHostPort(host, port)), nil
+}
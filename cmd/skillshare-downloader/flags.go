@@ -0,0 +1,264 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/vault"
+	"github.com/urfave/cli/v2"
+)
+
+func optionFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "config",
+			EnvVars:     []string{"SKILLSHARE_CONFIG"},
+			Usage:       "Config file (.yaml) with defaults and named profiles",
+			DefaultText: models.DefaultConfigPath(),
+			Category:    "Config:",
+		},
+		&cli.StringFlag{
+			Name:     "profile",
+			Aliases:  []string{"p"},
+			EnvVars:  []string{"SKILLSHARE_PROFILE"},
+			Usage:    "Named profile from the config file",
+			Category: "Config:",
+		},
+		&cli.StringFlag{
+			Name:     "class",
+			Aliases:  []string{"c"},
+			EnvVars:  []string{"SKILLSHARE_CLASS"},
+			Usage:    "Identity skillshare class id or skillshare class url",
+			Category: "Class:",
+		},
+		&cli.StringFlag{
+			Name:     "cookies",
+			Aliases:  []string{"co"},
+			EnvVars:  []string{"SKILLSHARE_COOKIES"},
+			Usage:    "String cookies for get content to skillshare",
+			Category: "Required Cookies:",
+		},
+		&cli.StringFlag{
+			Name:     "cookie-file",
+			Aliases:  []string{"cf"},
+			EnvVars:  []string{"SKILLSHARE_COOKIE_FILE"},
+			Usage:    "Cookie File (.txt) for get content to skillshare",
+			Category: "Required Cookies:",
+		},
+		&cli.StringFlag{
+			Name:     "vault",
+			EnvVars:  []string{"SKILLSHARE_VAULT"},
+			Usage:    fmt.Sprintf("Account name in the encrypted cookie vault, passphrase is read from %s or prompted", constants.EnvVaultPassphrase),
+			Category: "Required Cookies:",
+		},
+		&cli.StringFlag{
+			Name:        "vault-file",
+			EnvVars:     []string{"SKILLSHARE_VAULT_FILE"},
+			Usage:       "Encrypted cookie vault file",
+			DefaultText: vault.DefaultPath(),
+			Category:    "Required Cookies:",
+		},
+		&cli.IntFlag{
+			Name:        "quality",
+			Aliases:     []string{"q"},
+			EnvVars:     []string{"SKILLSHARE_QUALITY"},
+			Usage:       "Maximum video height to download, e.g. 720",
+			DefaultText: "best",
+			Category:    "Optional:",
+		},
+		&cli.StringFlag{
+			Name:        "language",
+			Aliases:     []string{"l"},
+			EnvVars:     []string{"SKILLSHARE_LANGUAGE"},
+			Usage:       "Language subtitle for download the video",
+			DefaultText: constants.DefaultLanguage,
+			Category:    "Optional:",
+		},
+		&cli.StringFlag{
+			Name:        "directory",
+			Aliases:     []string{"d"},
+			EnvVars:     []string{"SKILLSHARE_DIR"},
+			Usage:       "Directory name for save the video",
+			DefaultText: constants.DefaultDir,
+			Category:    "Optional:",
+		},
+		&cli.StringFlag{
+			Name:        "layout",
+			EnvVars:     []string{"SKILLSHARE_LAYOUT"},
+			Usage:       "Layout of the lessons, flat or units (one folder per unit)",
+			DefaultText: constants.DefaultLayout,
+			Category:    "Optional:",
+		},
+		&cli.StringFlag{
+			Name:        "output-template",
+			EnvVars:     []string{"SKILLSHARE_OUTPUT_TEMPLATE"},
+			Usage:       "Go template for the video filename, fields: .Class.ID .Class.Title .Teacher .Category .Unit.Number .Unit.Title .Index .Lesson.ID .Lesson.Title .Height .Lang .Ext, helpers: safe snake lower upper trim pad",
+			DefaultText: constants.DefaultOutputTemplate,
+			Category:    "Optional:",
+		},
+		&cli.StringFlag{
+			Name:        "subtitle-template",
+			EnvVars:     []string{"SKILLSHARE_SUBTITLE_TEMPLATE"},
+			Usage:       "Go template for the subtitle filename, same fields and helpers as output-template",
+			DefaultText: constants.DefaultSubtitleTemplate,
+			Category:    "Optional:",
+		},
+		&cli.IntFlag{
+			Name:        "worker",
+			Aliases:     []string{"w"},
+			EnvVars:     []string{"SKILLSHARE_WORKER"},
+			Usage:       "Worker for concurrent connection to download the video",
+			DefaultText: fmt.Sprint(constants.DefaultWorker),
+			Category:    "Optional:",
+		},
+		&cli.StringFlag{
+			Name:     "rate-limit",
+			EnvVars:  []string{"SKILLSHARE_RATE_LIMIT"},
+			Usage:    "Maximum download bandwidth per second for all connections, e.g. 500K or 2M",
+			Category: "Optional:",
+		},
+		&cli.StringFlag{
+			Name:        "cache-ttl",
+			EnvVars:     []string{"SKILLSHARE_CACHE_TTL"},
+			Usage:       "How long the cached class data is trusted, e.g. 12h, 0 to never expire",
+			DefaultText: constants.DefaultCacheTTL.String(),
+			Category:    "Cache:",
+		},
+		&cli.BoolFlag{
+			Name:        "refresh",
+			EnvVars:     []string{"SKILLSHARE_REFRESH"},
+			Usage:       "Ignore the cached data and fetch it again",
+			DefaultText: "false",
+			Category:    "Cache:",
+		},
+		&cli.BoolFlag{
+			Name:        "offline",
+			EnvVars:     []string{"SKILLSHARE_OFFLINE"},
+			Usage:       "Only use the cached data, fail when it is missing",
+			DefaultText: "false",
+			Category:    "Cache:",
+		},
+		&cli.BoolFlag{
+			Name:        "audio-only",
+			EnvVars:     []string{"SKILLSHARE_AUDIO_ONLY"},
+			Usage:       "Download only the audio of the lessons as tagged .m4a files",
+			DefaultText: "false",
+			Category:    "Optional:",
+		},
+		&cli.StringFlag{
+			Name:     "transcript",
+			EnvVars:  []string{"SKILLSHARE_TRANSCRIPT"},
+			Usage:    "Write a transcript of every lesson and the class from the subtitles, md or txt",
+			Category: "Optional:",
+		},
+		&cli.BoolFlag{
+			Name:        "transcript-timestamps",
+			EnvVars:     []string{"SKILLSHARE_TRANSCRIPT_TIMESTAMPS"},
+			Usage:       "Start the paragraphs of the transcript with their timestamp",
+			DefaultText: "false",
+			Category:    "Optional:",
+		},
+		&cli.StringFlag{
+			Name:     "dual-subs",
+			EnvVars:  []string{"SKILLSHARE_DUAL_SUBS"},
+			Usage:    "Merge the subtitles of two languages in one file, the first one on top, e.g. en-US,es",
+			Category: "Optional:",
+		},
+		&cli.StringFlag{
+			Name:        "dual-subs-format",
+			EnvVars:     []string{"SKILLSHARE_DUAL_SUBS_FORMAT"},
+			Usage:       "Format of the dual subtitles, vtt or ass",
+			DefaultText: constants.DualSubsVTT,
+			Category:    "Optional:",
+		},
+		&cli.BoolFlag{
+			Name:        "verbose",
+			Aliases:     []string{"vvv"},
+			EnvVars:     []string{"SKILLSHARE_VERBOSE"},
+			Usage:       "Verbose mode to see all logs",
+			DefaultText: "false",
+			Category:    "Optional:",
+		},
+		&cli.StringFlag{
+			Name:        "progress",
+			EnvVars:     []string{"SKILLSHARE_PROGRESS"},
+			Usage:       "Progress output: terminal, plain, quiet or json (events as json lines on stdout), terminal falls back to plain when stdout is not a terminal",
+			DefaultText: progressTerminal,
+			Category:    "Optional:",
+		},
+		&cli.BoolFlag{
+			Name:        "no-color",
+			EnvVars:     []string{"SKILLSHARE_NO_COLOR"},
+			Usage:       fmt.Sprintf("Disable the colors of the output, %s is honored too", constants.EnvNoColor),
+			DefaultText: "false",
+			Category:    "Optional:",
+		},
+	}
+}
+
+func flagConfig(cliCtx *cli.Context) models.Config {
+	config := models.Config{
+		UrlOrId:          cliCtx.String("class"),
+		Cookies:          cliCtx.String("cookies"),
+		CookieFile:       cliCtx.String("cookie-file"),
+		Vault:            cliCtx.String("vault"),
+		VaultFile:        cliCtx.String("vault-file"),
+		Lang:             cliCtx.String("language"),
+		Dir:              cliCtx.String("directory"),
+		Quality:          cliCtx.Int("quality"),
+		Layout:           cliCtx.String("layout"),
+		OutputTemplate:   cliCtx.String("output-template"),
+		SubtitleTemplate: cliCtx.String("subtitle-template"),
+		Worker:           cliCtx.Int("worker"),
+		RateLimit:        cliCtx.String("rate-limit"),
+		CacheTTL:         cliCtx.String("cache-ttl"),
+		IsRefresh:        cliCtx.Bool("refresh"),
+		IsOffline:        cliCtx.Bool("offline"),
+		Transcript:       cliCtx.String("transcript"),
+		DualSubs:         cliCtx.String("dual-subs"),
+		DualSubsFormat:   cliCtx.String("dual-subs-format"),
+		IsAudioOnly:      cliCtx.Bool("audio-only"),
+		IsTranscriptTime: cliCtx.Bool("transcript-timestamps"),
+		IsVerbose:        cliCtx.Bool("verbose"),
+		Sources:          make(map[string]string),
+	}
+
+	for _, flag := range optionFlags() {
+		name := flag.Names()[0]
+		if !cliCtx.IsSet(name) {
+			continue
+		}
+
+		config.Sources[name] = fmt.Sprintf("flag --%s", name)
+		envFlag, ok := flag.(cli.DocGenerationFlag)
+		if !ok {
+			continue
+		}
+
+		// flag set by environment variable have the same value with it
+		for _, env := range envFlag.GetEnvVars() {
+			if val, ok := os.LookupEnv(env); ok && val == fmt.Sprint(cliCtx.Value(name)) {
+				config.Sources[name] = fmt.Sprintf("env %s", env)
+			}
+		}
+	}
+
+	return config
+}
+
+// resolveConfig merge the config with precedence flag > environment > profile > defaults.
+func resolveConfig(cliCtx *cli.Context) (models.Config, error) {
+	fileConf, err := models.LoadFileConfig(cliCtx.String("config"))
+	if err != nil {
+		return models.Config{}, err
+	}
+
+	profileConf, err := fileConf.Config(cliCtx.String("profile"))
+	if err != nil {
+		return models.Config{}, err
+	}
+
+	return flagConfig(cliCtx).Merge(profileConf).Merge(models.DefaultConfig()), nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+
+	"github.com/rizalarfiyan/skillshare-downloader/logger"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/services"
+	"github.com/rizalarfiyan/skillshare-downloader/tui"
+)
+
+// runInteractive download the class with the terminal interface, the logs
+// are written after the interface is closed.
+func runInteractive(ctx context.Context, conf models.Config, color bool) error {
+	if !tui.IsSupported() {
+		return tui.ErrNotTerminal
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var logs bytes.Buffer
+	logger.SetOutput(&logs)
+	defer func() {
+		logger.SetOutput(os.Stderr)
+		_, _ = os.Stderr.Write(logs.Bytes())
+	}()
+
+	ui := tui.New(cancel, tui.Options{
+		Quality:  conf.Quality,
+		Language: conf.Lang,
+		Color:    color,
+	})
+	defer ui.Close()
+
+	err := services.NewSkillshare(ctx,
+		services.WithSelector(ui.Select),
+		services.WithReporter(ui),
+	).Run(conf)
+	ui.Finish(err)
+
+	if errors.Is(err, services.ErrCancelled) || errors.Is(err, context.Canceled) {
+		logger.Info("Download is cancelled")
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+	"github.com/rizalarfiyan/skillshare-downloader/library"
+	"github.com/rizalarfiyan/skillshare-downloader/logger"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/services"
+	"github.com/rizalarfiyan/skillshare-downloader/utils"
+	"github.com/urfave/cli/v2"
+)
+
+func libraryCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "library",
+		Usage: "Browse the index of downloaded classes",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "List all downloaded classes",
+				Flags: optionFlags(),
+				Action: withLibrary(func(cliCtx *cli.Context, lib *library.Library) error {
+					classes, err := lib.List()
+					if err != nil {
+						return err
+					}
+
+					printLibraryClasses(classes)
+					return nil
+				}),
+			},
+			{
+				Name:      "show",
+				Usage:     "Show the lessons of a downloaded class",
+				ArgsUsage: "<id>",
+				Flags:     optionFlags(),
+				Action: withLibrary(func(cliCtx *cli.Context, lib *library.Library) error {
+					id, err := strconv.Atoi(cliCtx.Args().First())
+					if err != nil {
+						return errors.New("class id is required")
+					}
+
+					class, err := lib.Get(id)
+					if err != nil {
+						return err
+					}
+
+					printLibraryClass(*class)
+					return nil
+				}),
+			},
+			{
+				Name:      "search",
+				Usage:     "Search classes by title, teacher, category or lesson title",
+				ArgsUsage: "<text>",
+				Flags:     optionFlags(),
+				Action: withLibrary(func(cliCtx *cli.Context, lib *library.Library) error {
+					text := strings.Join(cliCtx.Args().Slice(), " ")
+					if text == "" {
+						return errors.New("search text is required")
+					}
+
+					classes, err := lib.Search(text)
+					if err != nil {
+						return err
+					}
+
+					printLibraryClasses(classes)
+					return nil
+				}),
+			},
+			{
+				Name:  "rebuild",
+				Usage: "Rescan the class directories and rebuild the index",
+				Flags: optionFlags(),
+				Action: func(cliCtx *cli.Context) error {
+					setupLogger(cliCtx)
+
+					conf, err := resolveConfig(cliCtx)
+					if err != nil {
+						return err
+					}
+
+					count, err := services.NewLibrary().Rebuild(conf)
+					if err != nil {
+						return err
+					}
+
+					logger.Infof("Library index rebuilt with %d classes", count)
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func withLibrary(action func(cliCtx *cli.Context, lib *library.Library) error) cli.ActionFunc {
+	return func(cliCtx *cli.Context) error {
+		setupLogger(cliCtx)
+
+		conf, err := resolveConfig(cliCtx)
+		if err != nil {
+			return err
+		}
+
+		if !utils.IsExistPath(conf.Dir) {
+			return fmt.Errorf("directory %s not found", conf.Dir)
+		}
+
+		lib, err := library.Open(conf.Dir)
+		if err != nil {
+			return err
+		}
+		defer lib.Close()
+
+		return action(cliCtx, lib)
+	}
+}
+
+func printLibraryClasses(classes []models.LibraryClass) {
+	if len(classes) == 0 {
+		fmt.Println("No class found, run library rebuild to index the downloaded classes")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTITLE\tTEACHER\tCATEGORY\tLESSONS\tSIZE\tDOWNLOADED")
+	for _, class := range classes {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d/%d\t%s\t%s\n",
+			class.ID,
+			class.Title,
+			class.Teacher,
+			class.Category,
+			class.CountDownloaded(),
+			len(class.Lessons),
+			utils.FormatBytes(class.Size),
+			formatTime(class.DownloadedAt),
+		)
+	}
+	w.Flush()
+}
+
+func printLibraryClass(class models.LibraryClass) {
+	fmt.Printf("ID         : %d\n", class.ID)
+	fmt.Printf("Title      : %s\n", class.Title)
+	fmt.Printf("Teacher    : %s\n", class.Teacher)
+	fmt.Printf("Category   : %s\n", class.Category)
+	fmt.Printf("Directory  : %s\n", class.Dir)
+	fmt.Printf("Languages  : %s\n", strings.Join(class.Languages, ", "))
+	fmt.Printf("Size       : %s\n", utils.FormatBytes(class.Size))
+	fmt.Printf("Downloaded : %s\n\n", formatTime(class.DownloadedAt))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tTITLE\tUNIT\tDURATION\tSIZE\tSUBTITLES\tPATH")
+	for _, lesson := range class.Lessons {
+		var langs []string
+		for _, sub := range lesson.Subtitles {
+			langs = append(langs, sub.Lang)
+		}
+
+		path := lesson.Path
+		if path == "" {
+			path = "-"
+		}
+
+		fmt.Fprintf(w, "%03d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			lesson.Index,
+			lesson.Title,
+			lesson.Unit,
+			lesson.Duration,
+			utils.FormatBytes(lesson.Size),
+			strings.Join(langs, ","),
+			path,
+		)
+	}
+	w.Flush()
+}
+
+func formatTime(value time.Time) string {
+	if value.IsZero() {
+		return "-"
+	}
+
+	return value.Format(constants.DefaultTimestampFormat)
+}
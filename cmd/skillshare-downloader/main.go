@@ -10,9 +10,7 @@ import (
 
 	"github.com/rizalarfiyan/skillshare-downloader/constants"
 	"github.com/rizalarfiyan/skillshare-downloader/logger"
-	"github.com/rizalarfiyan/skillshare-downloader/models"
 	"github.com/rizalarfiyan/skillshare-downloader/services"
-	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
 )
 
@@ -28,6 +26,7 @@ var (
 
 func init() {
 	logger.Init()
+	constants.AppVersion = appVersion
 }
 
 func main() {
@@ -78,74 +77,46 @@ func main() {
 		HelpName:  "Skillshare Downloader",
 		UsageText: "skillshare-dl --class <class> --cookie-file <cookie-path> [args and such]\n",
 		ArgsUsage: "[args and such]",
-		Flags: []cli.Flag{
-			&cli.StringFlag{
-				Name:     "class",
-				Aliases:  []string{"c"},
-				Usage:    "Identity skillshare class id or skillshare class url",
-				Category: "Class:",
-			},
-			&cli.StringFlag{
-				Name:     "cookies",
-				Aliases:  []string{"co"},
-				Usage:    "String cookies for get content to skillshare",
-				Category: "Required Cookies:",
-			},
-			&cli.StringFlag{
-				Name:     "cookie-file",
-				Aliases:  []string{"cf"},
-				Usage:    "Cookie File (.txt) for get content to skillshare",
-				Category: "Required Cookies:",
-			},
-			&cli.StringFlag{
-				Name:        "language",
-				Aliases:     []string{"l"},
-				Usage:       "Language subtitle for download the video",
-				DefaultText: constants.DefaultLanguage,
-				Category:    "Optional:",
-			},
-			&cli.StringFlag{
-				Name:        "directory",
-				Aliases:     []string{"d"},
-				Usage:       "Directory name for save the video",
-				DefaultText: constants.DefaultDir,
-				Category:    "Optional:",
-			},
-			&cli.IntFlag{
-				Name:        "worker",
-				Aliases:     []string{"w"},
-				Usage:       "Worker for concurrent connection to download the video",
-				DefaultText: fmt.Sprint(constants.DefaultWorker),
-				Category:    "Optional:",
-			},
-			&cli.BoolFlag{
-				Name:        "verbose",
-				Aliases:     []string{"vvv"},
-				Usage:       "Verbose mode to see all logs",
-				DefaultText: "false",
-				Category:    "Optional:",
-			},
+		Flags: append(optionFlags(), &cli.BoolFlag{
+			Name:     "interactive",
+			Aliases:  []string{"i"},
+			EnvVars:  []string{"SKILLSHARE_INTERACTIVE"},
+			Usage:    "Pick the lessons, quality and languages in a terminal interface",
+			Category: "Optional:",
+		}),
+		Commands: []*cli.Command{
+			configCommand(),
+			authCommand(),
+			libraryCommand(),
+			verifyCommand(),
+			searchCommand(),
+			feedCommand(),
+			exportCommand(),
+			importCommand(),
+			syncCommand(),
+			watchCommand(),
+			serveCommand(),
+			queueCommand(),
 		},
 		Action: func(cliCtx *cli.Context) error {
-			isVerbose := cliCtx.Bool("verbose")
-			if isVerbose {
-				logger.SetLevel(logrus.DebugLevel)
+			setupLogger(cliCtx)
+
+			conf, err := resolveConfig(cliCtx)
+			if err != nil {
+				return err
+			}
+
+			if cliCtx.Bool("interactive") {
+				return runInteractive(ctx, conf, isColor(cliCtx))
 			}
 
-			err := services.NewSkillshare(ctx).Run(models.Config{
-				UrlOrId:    cliCtx.String("class"),
-				Cookies:    cliCtx.String("cookies"),
-				CookieFile: cliCtx.String("cookie-file"),
-				Lang:       cliCtx.String("language"),
-				Dir:        cliCtx.String("directory"),
-				Worker:     cliCtx.Int("worker"),
-				IsVerbose:  isVerbose,
-			})
+			r, err := newReporter(cliCtx)
 			if err != nil {
 				return err
 			}
 
-			return nil
+			splash(cliCtx)
+			return services.NewSkillshare(ctx, services.WithReporter(r)).Run(conf)
 		},
 	}
 
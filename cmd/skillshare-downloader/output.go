@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+	"github.com/rizalarfiyan/skillshare-downloader/logger"
+	"github.com/rizalarfiyan/skillshare-downloader/reporter"
+	"github.com/rizalarfiyan/skillshare-downloader/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	progressTerminal = "terminal"
+	progressPlain    = "plain"
+	progressQuiet    = "quiet"
+	progressJson     = "json"
+)
+
+func setupLogger(cliCtx *cli.Context) {
+	if cliCtx.Bool("verbose") {
+		logger.SetLevel(logrus.DebugLevel)
+	}
+
+	if cliCtx.Bool("no-color") {
+		logger.DisableColor()
+	}
+}
+
+// isColor returns false when the stdout is not a terminal or the color is
+// disabled by --no-color or NO_COLOR.
+func isColor(cliCtx *cli.Context) bool {
+	return isColorFile(cliCtx, os.Stdout)
+}
+
+func isColorFile(cliCtx *cli.Context, file *os.File) bool {
+	return !cliCtx.Bool("no-color") && !utils.IsNoColor() && utils.IsTerminal(file)
+}
+
+// newReporter returns the reporter of the --progress flag, the terminal
+// spinner and bars are hidden in verbose mode to keep the logs readable and
+// replaced by plain lines when the stderr they write to is not a terminal.
+func newReporter(cliCtx *cli.Context) (reporter.Reporter, error) {
+	switch cliCtx.String("progress") {
+	case "", progressTerminal:
+		if cliCtx.Bool("verbose") {
+			return reporter.NewQuiet(), nil
+		}
+		if !utils.IsTerminal(os.Stderr) {
+			return reporter.NewPlain(os.Stdout), nil
+		}
+		return reporter.NewTerminal(isColorFile(cliCtx, os.Stderr)), nil
+	case progressPlain:
+		return reporter.NewPlain(os.Stdout), nil
+	case progressQuiet:
+		return reporter.NewQuiet(), nil
+	case progressJson:
+		return reporter.NewJSON(os.Stdout), nil
+	}
+	return nil, fmt.Errorf("unknown progress %q, use %s, %s, %s or %s", cliCtx.String("progress"), progressTerminal, progressPlain, progressQuiet, progressJson)
+}
+
+func splash(cliCtx *cli.Context) {
+	switch cliCtx.String("progress") {
+	case "", progressTerminal:
+		fmt.Printf("\n%s\n\n", constants.SplashScreen)
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/rizalarfiyan/skillshare-downloader/logger"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/queue"
+	"github.com/rizalarfiyan/skillshare-downloader/services"
+	"github.com/rizalarfiyan/skillshare-downloader/utils"
+	"github.com/urfave/cli/v2"
+)
+
+func queueCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "queue",
+		Usage: "Manage the persisted download queue",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "List the classes in the queue",
+				Flags: append(optionFlags(), &cli.BoolFlag{
+					Name:  "json",
+					Usage: "Print the queue as json",
+				}),
+				Action: withQueue(func(cliCtx *cli.Context, conf models.Config, q *queue.Queue) error {
+					classes, err := q.List()
+					if err != nil {
+						return err
+					}
+
+					if cliCtx.Bool("json") {
+						return printJson(classes)
+					}
+
+					printQueue(classes)
+					return nil
+				}),
+			},
+			{
+				Name:      "retry",
+				Usage:     "Download again the failed and interrupted classes, or the given classes",
+				ArgsUsage: "[class...]",
+				Flags:     optionFlags(),
+				Action: withQueue(func(cliCtx *cli.Context, conf models.Config, q *queue.Queue) error {
+					classes, err := retryClasses(cliCtx, q)
+					if err != nil {
+						return err
+					}
+
+					if len(classes) == 0 {
+						fmt.Println("No class to retry")
+						return nil
+					}
+
+					r, err := newReporter(cliCtx)
+					if err != nil {
+						return err
+					}
+
+					failed := 0
+					for _, class := range classes {
+						conf.UrlOrId = class
+						err := services.NewSkillshare(cliCtx.Context, services.WithReporter(r)).Run(conf)
+						if err != nil {
+							logger.Warningf("Failed retry class %s: %s", class, err.Error())
+							failed++
+						}
+					}
+
+					if failed > 0 {
+						return fmt.Errorf("failed retry %d of %d classes", failed, len(classes))
+					}
+					return nil
+				}),
+			},
+			{
+				Name:  "clear",
+				Usage: "Remove the done and cancelled classes from the queue",
+				Flags: append(optionFlags(), &cli.BoolFlag{
+					Name:  "all",
+					Usage: "Remove every class, including the failed and unfinished",
+				}),
+				Action: withQueue(func(cliCtx *cli.Context, conf models.Config, q *queue.Queue) error {
+					status := []string{models.JobDone, models.JobCancelled}
+					if cliCtx.Bool("all") {
+						status = nil
+					}
+
+					count, err := q.Clear(status...)
+					if err != nil {
+						return err
+					}
+
+					logger.Infof("Removed %d classes from the queue", count)
+					return nil
+				}),
+			},
+		},
+	}
+}
+
+func withQueue(action func(cliCtx *cli.Context, conf models.Config, q *queue.Queue) error) cli.ActionFunc {
+	return func(cliCtx *cli.Context) error {
+		setupLogger(cliCtx)
+
+		conf, err := resolveConfig(cliCtx)
+		if err != nil {
+			return err
+		}
+
+		return action(cliCtx, conf, queue.New(conf.Dir))
+	}
+}
+
+// retryClasses returns the given classes, or the classes of the queue which
+// are not done.
+func retryClasses(cliCtx *cli.Context, q *queue.Queue) ([]string, error) {
+	if cliCtx.Args().Present() {
+		return cliCtx.Args().Slice(), nil
+	}
+
+	classes, err := q.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, class := range classes {
+		if class.Status != models.JobDone {
+			result = append(result, strconv.Itoa(class.ClassID))
+		}
+	}
+	return result, nil
+}
+
+func printQueue(classes []models.QueueClass) {
+	if len(classes) == 0 {
+		fmt.Println("Download queue is empty")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTITLE\tSTATUS\tLESSONS\tATTEMPTS\tDOWNLOADED\tUPDATED\tERROR")
+	for _, class := range classes {
+		lastError := class.LastError
+		if lastError == "" {
+			lastError = "-"
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d/%d\t%d\t%s\t%s\t%s\n",
+			class.ClassID,
+			class.Title,
+			class.Status,
+			class.CountStatus(models.JobDone),
+			len(class.Lessons),
+			class.Attempts,
+			utils.FormatBytes(class.BytesDone()),
+			formatTime(class.UpdatedAt),
+			lastError,
+		)
+	}
+	w.Flush()
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+	"github.com/rizalarfiyan/skillshare-downloader/logger"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/services"
+	"github.com/rizalarfiyan/skillshare-downloader/utils"
+	"github.com/urfave/cli/v2"
+)
+
+func searchCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "search",
+		Usage:     "Search a phrase in the subtitles of the downloaded classes",
+		ArgsUsage: "<phrase>",
+		Flags: append(optionFlags(),
+			&cli.IntFlag{
+				Name:        "limit",
+				Usage:       "Maximum number of matches, 0 for all",
+				Value:       constants.DefaultSearchLimit,
+				DefaultText: fmt.Sprint(constants.DefaultSearchLimit),
+			},
+			&cli.BoolFlag{
+				Name:  "reference",
+				Usage: "Print file:timestamp of every match, e.g. for mpv --start=<timestamp> <file>",
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Print the matches as json",
+			},
+		),
+		Action: func(cliCtx *cli.Context) error {
+			setupLogger(cliCtx)
+
+			phrase := strings.Join(cliCtx.Args().Slice(), " ")
+			if strings.TrimSpace(phrase) == "" {
+				return errors.New("search phrase is required")
+			}
+
+			conf, err := resolveConfig(cliCtx)
+			if err != nil {
+				return err
+			}
+
+			if !utils.IsExistPath(conf.Dir) {
+				return fmt.Errorf("directory %s not found", conf.Dir)
+			}
+
+			search := services.NewSearch()
+			stats, err := search.Update(conf)
+			if err != nil {
+				return err
+			}
+			logger.Debugf("Search index updated, %d indexed, %d removed, %d subtitles", stats.Indexed, stats.Removed, stats.Total)
+
+			results, err := search.Find(conf, phrase, cliCtx.Int("limit"))
+			if err != nil {
+				return err
+			}
+
+			if cliCtx.Bool("json") {
+				return printJson(results)
+			}
+
+			printSearchResults(results, cliCtx.Bool("reference"))
+			return nil
+		},
+	}
+}
+
+func printSearchResults(results []models.SearchResult, isReference bool) {
+	if len(results) == 0 {
+		fmt.Println("No match found")
+		return
+	}
+
+	if isReference {
+		for _, result := range results {
+			fmt.Println(result.Reference())
+		}
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CLASS\t#\tLESSON\tTIME\tCONTEXT")
+	for _, result := range results {
+		fmt.Fprintf(w, "%s\t%03d\t%s\t%s\t%s\n", result.ClassTitle, result.Index, result.Lesson, result.Timestamp, result.Context)
+	}
+	w.Flush()
+}
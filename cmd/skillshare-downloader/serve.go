@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+	"github.com/rizalarfiyan/skillshare-downloader/logger"
+	"github.com/rizalarfiyan/skillshare-downloader/server"
+	"github.com/urfave/cli/v2"
+)
+
+func serveCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "serve",
+		Usage: "Run a local http api to queue and follow the downloads",
+		Flags: append(optionFlags(),
+			&cli.StringFlag{
+				Name:    "listen",
+				EnvVars: []string{"SKILLSHARE_LISTEN"},
+				Usage:   "Address of the http api",
+				Value:   constants.DefaultListen,
+			},
+			&cli.IntFlag{
+				Name:    "jobs",
+				EnvVars: []string{"SKILLSHARE_JOBS"},
+				Usage:   "Number of classes downloaded at the same time",
+				Value:   1,
+			},
+			&cli.StringFlag{
+				Name:    "token",
+				EnvVars: []string{"SKILLSHARE_TOKEN"},
+				Usage:   "Bearer token required by the api, or token query for the events",
+			},
+			&cli.StringFlag{
+				Name:    "allow-origin",
+				EnvVars: []string{"SKILLSHARE_ALLOW_ORIGIN"},
+				Usage:   "Origin allowed to call the api from a browser, * for any",
+				Value:   constants.DefaultAllowOrigin,
+			},
+		),
+		Action: func(cliCtx *cli.Context) error {
+			setupLogger(cliCtx)
+
+			conf, err := resolveConfig(cliCtx)
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(cliCtx.Context, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			srv := server.New(ctx, conf, server.Options{
+				Jobs:        cliCtx.Int("jobs"),
+				Token:       cliCtx.String("token"),
+				AllowOrigin: cliCtx.String("allow-origin"),
+				Listen:      cliCtx.String("listen"),
+			})
+			if err := srv.Start(); err != nil {
+				return err
+			}
+
+			httpServer := &http.Server{
+				Addr:              cliCtx.String("listen"),
+				Handler:           srv.Handler(),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+
+			go func() {
+				<-ctx.Done()
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				_ = httpServer.Shutdown(shutdownCtx)
+			}()
+
+			logger.Infof("Listen on http://%s", httpServer.Addr)
+			err = httpServer.ListenAndServe()
+			if errors.Is(err, http.ErrServerClosed) {
+				logger.Info("Server is stopped")
+				return nil
+			}
+			return err
+		},
+	}
+}
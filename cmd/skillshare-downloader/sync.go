@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/rizalarfiyan/skillshare-downloader/logger"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/services"
+	"github.com/urfave/cli/v2"
+)
+
+func syncCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "sync",
+		Usage:     "Download the lessons added or updated since the class was downloaded",
+		ArgsUsage: "<class|--all>",
+		Flags: append(optionFlags(), &cli.BoolFlag{
+			Name:  "all",
+			Usage: "Sync every downloaded class",
+		}),
+		Action: func(cliCtx *cli.Context) error {
+			setupLogger(cliCtx)
+
+			conf, err := resolveConfig(cliCtx)
+			if err != nil {
+				return err
+			}
+
+			classes := cliCtx.Args().Slice()
+			if cliCtx.Bool("all") {
+				ids, err := services.NewLibrary().ClassIDs(conf)
+				if err != nil {
+					return err
+				}
+
+				classes = nil
+				for _, id := range ids {
+					classes = append(classes, strconv.Itoa(id))
+				}
+			} else if len(classes) == 0 && conf.UrlOrId != "" {
+				classes = []string{conf.UrlOrId}
+			}
+
+			if len(classes) == 0 {
+				return errors.New("class id or url is required, or use --all")
+			}
+
+			r, err := newReporter(cliCtx)
+			if err != nil {
+				return err
+			}
+
+			failed := 0
+			for _, class := range classes {
+				conf.UrlOrId = class
+				syncLog, err := services.NewSkillshare(cliCtx.Context, services.WithReporter(r)).Sync(conf)
+				if err != nil {
+					logger.Warningf("Failed sync class %s: %s", class, err.Error())
+					failed++
+					continue
+				}
+
+				printSyncLog(syncLog)
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("failed sync %d of %d classes", failed, len(classes))
+			}
+			return nil
+		},
+	}
+}
+
+func printSyncLog(syncLog *models.SyncLog) {
+	logger.Infof("[%d] %s: %d added, %d updated, %d removed, %d moved",
+		syncLog.ClassID,
+		syncLog.Title,
+		syncLog.Count(models.SyncAdded),
+		syncLog.Count(models.SyncUpdated),
+		syncLog.Count(models.SyncRemoved),
+		syncLog.Count(models.SyncMoved),
+	)
+
+	for _, change := range syncLog.Changes {
+		index := change.Index
+		if change.Kind == models.SyncRemoved {
+			index = change.OldIndex
+		}
+		logger.Infof("[%d] %s %03d. %s %s", syncLog.ClassID, change.Kind, index, change.Title, change.Reason)
+	}
+
+	if syncLog.Archive != "" {
+		logger.Infof("[%d] Replaced files are archived in %s", syncLog.ClassID, syncLog.Archive)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/services"
+	"github.com/urfave/cli/v2"
+)
+
+func verifyCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "verify",
+		Usage:     "Audit the downloaded classes, exit non-zero when a problem is found",
+		ArgsUsage: "[class...]",
+		Flags: append(optionFlags(), &cli.BoolFlag{
+			Name:  "json",
+			Usage: "Print the report as json",
+		}),
+		Action: func(cliCtx *cli.Context) error {
+			setupLogger(cliCtx)
+
+			conf, err := resolveConfig(cliCtx)
+			if err != nil {
+				return err
+			}
+
+			var ids []int
+			for _, arg := range cliCtx.Args().Slice() {
+				id, err := models.ParseClassID(arg)
+				if err != nil {
+					return fmt.Errorf("%s: %w", arg, err)
+				}
+				ids = append(ids, id)
+			}
+
+			report, err := services.NewVerify().Run(conf, ids)
+			if err != nil {
+				return err
+			}
+
+			if cliCtx.Bool("json") {
+				err = printJson(report)
+			} else {
+				printVerifyReport(report)
+			}
+			if err != nil {
+				return err
+			}
+
+			for _, class := range report {
+				if !class.IsValid() {
+					return cli.Exit("", 1)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func printJson(value any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func printVerifyReport(report []models.VerifyClass) {
+	if len(report) == 0 {
+		fmt.Println("No downloaded class found")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CLASS\t#\tLESSON\tISSUE\tPATH\tDETAIL")
+	for _, class := range report {
+		if class.IsValid() {
+			fmt.Fprintf(w, "%d\t-\t%d lessons\tok\t%s\t-\n", class.ID, class.Lessons, class.Dir)
+			continue
+		}
+
+		for _, issue := range class.Issues {
+			index, lesson := "-", "-"
+			if issue.Index > 0 {
+				index = fmt.Sprintf("%03d", issue.Index)
+				lesson = issue.Lesson
+			}
+
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", class.ID, index, lesson, issue.Kind, issue.Path, issue.Detail)
+		}
+	}
+	w.Flush()
+}
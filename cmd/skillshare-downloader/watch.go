@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/services"
+	"github.com/urfave/cli/v2"
+)
+
+func watchCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "watch",
+		Usage: "Sync a list of classes on a schedule until stopped",
+		Flags: append(optionFlags(),
+			&cli.StringFlag{
+				Name:     "classes",
+				EnvVars:  []string{"SKILLSHARE_WATCH_CLASSES"},
+				Usage:    "File with one class id or url per line, read again on every cycle",
+				Required: true,
+			},
+			&cli.DurationFlag{
+				Name:    "interval",
+				EnvVars: []string{"SKILLSHARE_WATCH_INTERVAL"},
+				Usage:   "Time between the start of two cycles",
+				Value:   constants.DefaultWatchInterval,
+			},
+			&cli.BoolFlag{
+				Name:  "once",
+				Usage: "Run one cycle and exit",
+			},
+		),
+		Action: func(cliCtx *cli.Context) error {
+			setupLogger(cliCtx)
+
+			conf, err := resolveConfig(cliCtx)
+			if err != nil {
+				return err
+			}
+
+			r, err := newReporter(cliCtx)
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(cliCtx.Context, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			return services.NewWatch(ctx, services.WithReporter(r)).Run(conf, models.WatchOptions{
+				ClassesFile: cliCtx.String("classes"),
+				Interval:    cliCtx.Duration("interval"),
+				Once:        cliCtx.Bool("once"),
+			})
+		},
+	}
+}
@@ -6,23 +6,91 @@ import (
 )
 
 const (
-	DefaultLanguage        = "en-US"
-	DefaultDir             = "./downloaded"
-	DefaultLogFormat       = "[%lvl%]: %time% - %msg% \n"
-	DefaultTimestampFormat = time.DateTime
+	DefaultLanguage          = "en-US"
+	DefaultDir               = "./downloaded"
+	DefaultLayout            = LayoutFlat
+	DefaultOutputTemplate    = "{{pad .Index 3}}_{{snake .Lesson.Title}}{{.Ext}}"
+	DefaultSubtitleTemplate  = "{{pad .Index 3}}_{{snake .Lesson.Title}}{{.Ext}}"
+	SubtitleLangTemplate     = ".{{lower .Lang}}"
+	DefaultLogFormat         = "[%lvl%]: %time% - %msg% \n"
+	DefaultTimestampFormat   = time.DateTime
+	DefaultCacheTTL          = 24 * time.Hour
+	SignedURLMargin          = 5 * time.Minute
+	DefaultWatchInterval     = 6 * time.Hour
+	QueueSaveInterval        = 5 * time.Second
+	PlainProgressInterval    = 5 * time.Second
+	PlainProgressPercent     = 10
+	MaxNameBytes             = 200
+	MaxFilenameBytes         = 255
+	DefaultListen            = "127.0.0.1:8080"
+	DefaultFeedListen        = ":8090"
+	FeedCategory             = "Education"
+	FeedLibraryTitle         = "Skillshare Library"
+	DefaultAllowOrigin       = "https://www.skillshare.com"
+	WatchBackoff             = 5 * time.Minute
+	WatchMaxBackoff          = 24 * time.Hour
+	TranscriptParagraphGap   = 2 * time.Second
+	TranscriptParagraphWords = 120
+	TranscriptOverlapWords   = 30
+	DefaultSearchLimit       = 20
+	SearchWindowCues         = 3
+	SearchContextBytes       = 160
+	DualSubsMaxDrift         = 2 * time.Second
+	DualSubsSecondaryScale   = 75
+	DualSubsFontSize         = 64
+	DefaultBundleFormat      = BundleZip
+	BundleVersion            = 1
 
+	ConfigDirName       = "skillshare-downloader"
+	ConfigFileName      = "config.yaml"
+	VaultFileName       = "vault.json"
+	DefaultVaultAccount = "default"
+	EnvVaultPassphrase  = "SKILLSHARE_VAULT_PASSPHRASE"
+	EnvNoColor          = "NO_COLOR"
 	FolderName          = "[%d] %s"
+	FolderUnit          = "%02d - %s"
+	FolderArchive       = "archive"
+	ArchiveTimeFormat   = "20060102-150405"
 	FilenameClassData   = "class_data.json"
+	FilenameClassMeta   = "class_data.meta.json"
+	FilenameClassFiles  = "class_files.json"
+	FilenameLibrary     = "library.db"
+	FilenameQueue       = "queue.db"
 	FilenameVideoData   = "%03d_%s_data.json"
-	FilenameVideo       = "%03d_%s%s"
-	FilenameSubtitle    = "%03d_%s%s"
+	FilenameChangeLog   = "changelog.jsonl"
+	FilenameWatchState  = "watch.json"
+	ExtAudio            = ".m4a"
+	FilenameTranscript  = "transcript.md"
+	FilenameSearch      = "search.db"
+	FilenameFeed        = "feed.xml"
+	FilenameManifest    = "manifest.json"
+	FolderImport        = ".import-"
 	ProgressBarTemplate = `{{counters .}} - {{ bar . "[" "=" (cycle . ">" ) "-" "]"}} {{percent .}} {{speed .}}`
 
+	// Format of the transcripts
+	TranscriptMarkdown = "md"
+	TranscriptText     = "txt"
+
+	// Format of the dual language subtitles
+	DualSubsVTT = "vtt"
+	DualSubsASS = "ass"
+
+	// Format of the class bundles
+	BundleZip    = "zip"
+	BundleTarZst = "tar.zst"
+
+	// Layout of lessons inside the video directory
+	LayoutFlat  = "flat"
+	LayoutUnits = "units"
+
 	// Credentials SKillshare
 	PolicyKey = "BCpkADawqM2OOcM6njnM7hf9EaK6lIFlqiXB0iWjqGWUQjU7R8965xUvIQNqdQbnDTLz0IAO7E6Ir2rIbXJtFdzrGtitoee0n1XXRliD-RH9A-svuvNW9qgo3Bh34HEZjXjG4Nml4iyz3KqF"
 )
 
 var (
+	// AppVersion is filled by the main package from the build information
+	AppVersion string = "not set"
+
 	MaxWorker     int = runtime.NumCPU()
 	DefaultWorker int = MaxWorker
 
@@ -1,6 +1,8 @@
 package constants
 
 const (
-	APIClass = "https://api.skillshare.com/classes/%d"
-	APIVideo = "https://edge.api.brightcove.com/playback/v1/accounts/%d/videos/%d"
+	CookieDomain = "skillshare.com"
+	APIClass     = "https://api.skillshare.com/classes/%d"
+	APIMe        = "https://api.skillshare.com/me"
+	APIVideo     = "https://edge.api.brightcove.com/playback/v1/accounts/%d/videos/%d"
 )
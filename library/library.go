@@ -0,0 +1,135 @@
+package library
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	ErrClassNotFound = errors.New("class not found in library")
+
+	bucketClasses = []byte("classes")
+)
+
+type Library struct {
+	db *bolt.DB
+}
+
+// Open the library index in the download root, it is created when missing.
+func Open(root string) (*Library, error) {
+	pathfile := filepath.Join(root, constants.FilenameLibrary)
+	db, err := bolt.Open(pathfile, 0o644, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open library %s: %w", pathfile, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucketClasses)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Library{db: db}, nil
+}
+
+func (l *Library) Close() error {
+	return l.db.Close()
+}
+
+func (l *Library) Put(class models.LibraryClass) error {
+	value, err := json.Marshal(class)
+	if err != nil {
+		return err
+	}
+
+	return l.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketClasses).Put(classKey(class.ID), value)
+	})
+}
+
+func (l *Library) Get(id int) (*models.LibraryClass, error) {
+	dest := &models.LibraryClass{}
+	err := l.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(bucketClasses).Get(classKey(id))
+		if value == nil {
+			return fmt.Errorf("%w: %d", ErrClassNotFound, id)
+		}
+		return json.Unmarshal(value, dest)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+func (l *Library) Delete(id int) error {
+	return l.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketClasses).Delete(classKey(id))
+	})
+}
+
+// List returns the classes sorted by title.
+func (l *Library) List() ([]models.LibraryClass, error) {
+	var classes []models.LibraryClass
+	err := l.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketClasses).ForEach(func(_, value []byte) error {
+			var class models.LibraryClass
+			if err := json.Unmarshal(value, &class); err != nil {
+				return err
+			}
+			classes = append(classes, class)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(classes, func(i, j int) bool {
+		return classes[i].Title < classes[j].Title
+	})
+	return classes, nil
+}
+
+func (l *Library) Search(text string) ([]models.LibraryClass, error) {
+	classes, err := l.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []models.LibraryClass
+	for _, class := range classes {
+		if class.Match(text) {
+			result = append(result, class)
+		}
+	}
+	return result, nil
+}
+
+// Reset remove all classes, used before rebuilding the index.
+func (l *Library) Reset() error {
+	return l.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(bucketClasses); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucket(bucketClasses)
+		return err
+	})
+}
+
+func classKey(id int) []byte {
+	return []byte(strconv.Itoa(id))
+}
@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"io"
 	"os"
 
 	"github.com/rizalarfiyan/skillshare-downloader/constants"
@@ -19,10 +20,23 @@ func Init() {
 		Formatter: &utils.Logrus{
 			TimestampFormat: constants.DefaultTimestampFormat,
 			LogFormat:       constants.DefaultLogFormat,
+			NoColor:         utils.IsNoColor() || !utils.IsTerminal(os.Stderr),
 		},
 	}
 }
 
+// DisableColor remove the color of the level in every log.
+func DisableColor() {
+	if formatter, ok := logger.Formatter.(*utils.Logrus); ok {
+		formatter.NoColor = true
+	}
+}
+
+func IsColor() bool {
+	formatter, ok := logger.Formatter.(*utils.Logrus)
+	return ok && !formatter.NoColor
+}
+
 func Get() *logrus.Logger {
 	return logger
 }
@@ -31,6 +45,10 @@ func SetLevel(level logrus.Level) {
 	logger.SetLevel(level)
 }
 
+func SetOutput(out io.Writer) {
+	logger.SetOutput(out)
+}
+
 func Debug(args ...interface{}) {
 	logger.Debug(args...)
 }
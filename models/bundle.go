@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Manifest describe the class directory packaged in a bundle, the path of
+// the files are relative to the class directory.
+type Manifest struct {
+	Version   int            `json:"version"`
+	ClassID   int            `json:"class_id"`
+	Title     string         `json:"title"`
+	Teacher   string         `json:"teacher"`
+	Dir       string         `json:"dir"`
+	CreatedAt time.Time      `json:"created_at"`
+	Files     []ManifestFile `json:"files"`
+}
+
+type ManifestFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Size returns the total size of the files.
+func (m Manifest) Size() int64 {
+	var size int64
+	for _, file := range m.Files {
+		size += file.Size
+	}
+	return size
+}
+
+type ExportOptions struct {
+	// Format is zip or tar.zst.
+	Format string
+	// Output is the path of the bundle, the name of the class in the
+	// current directory when it is empty.
+	Output string
+}
+
+type ImportOptions struct {
+	// IsForce replace the class when it is already in the download root.
+	IsForce bool
+}
@@ -5,30 +5,225 @@ import (
 	"fmt"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
+	"strings"
+	"text/template"
+	"time"
 
 	"github.com/rizalarfiyan/skillshare-downloader/constants"
 	"github.com/rizalarfiyan/skillshare-downloader/logger"
 	"github.com/rizalarfiyan/skillshare-downloader/utils"
+	"github.com/rizalarfiyan/skillshare-downloader/vault"
 )
 
 type Config struct {
-	UrlOrId    string
-	Cookies    string
-	CookieFile string
-	Lang       string
-	Dir        string
-	Worker     int
-	IsVerbose  bool
+	UrlOrId          string
+	Cookies          string
+	CookieFile       string
+	Vault            string
+	VaultFile        string
+	Lang             string
+	Dir              string
+	Quality          int
+	Layout           string
+	OutputTemplate   string
+	SubtitleTemplate string
+	Worker           int
+	RateLimit        string
+	CacheTTL         string
+	Transcript       string
+	DualSubs         string
+	DualSubsFormat   string
+	IsRefresh        bool
+	IsOffline        bool
+	IsAudioOnly      bool
+	IsTranscriptTime bool
+	IsVerbose        bool
+
+	// Sources holds where each value came from, keyed by the flag name.
+	Sources map[string]string
 }
 
 type AppConfig struct {
-	ID        int
-	Cookies   string
-	Lang      string
-	Dir       string
-	Worker    int
-	IsVerbose bool
+	ID               int
+	Cookies          string
+	CookieExpiry     time.Time
+	Lang             string
+	Dir              string
+	Quality          int
+	Layout           string
+	OutputTemplate   *template.Template
+	SubtitleTemplate *template.Template
+	Worker           int
+	RateLimit        int64
+	CacheTTL         time.Duration
+	Transcript       string
+	DualSubs         []string
+	DualSubsFormat   string
+	IsRefresh        bool
+	IsOffline        bool
+	IsAudioOnly      bool
+	IsTranscriptTime bool
+	IsVerbose        bool
+}
+
+func DefaultConfig() Config {
+	config := Config{
+		Lang:             constants.DefaultLanguage,
+		Dir:              constants.DefaultDir,
+		Layout:           constants.DefaultLayout,
+		OutputTemplate:   constants.DefaultOutputTemplate,
+		SubtitleTemplate: constants.DefaultSubtitleTemplate,
+		Worker:           constants.DefaultWorker,
+		CacheTTL:         constants.DefaultCacheTTL.String(),
+	}
+	return config.WithSource("default")
+}
+
+// Merge fills the empty values of config with the values of base.
+func (config Config) Merge(base Config) Config {
+	sources := make(map[string]string)
+	for key, source := range config.Sources {
+		sources[key] = source
+	}
+
+	inherit := func(key string) {
+		if source, ok := base.Sources[key]; ok {
+			sources[key] = source
+		}
+	}
+
+	if config.UrlOrId == "" {
+		config.UrlOrId = base.UrlOrId
+		inherit("class")
+	}
+	if config.Cookies == "" && config.CookieFile == "" && config.Vault == "" {
+		config.Cookies = base.Cookies
+		config.CookieFile = base.CookieFile
+		config.Vault = base.Vault
+		inherit("cookies")
+		inherit("cookie-file")
+		inherit("vault")
+	}
+	if config.VaultFile == "" {
+		config.VaultFile = base.VaultFile
+		inherit("vault-file")
+	}
+	if config.Lang == "" {
+		config.Lang = base.Lang
+		inherit("language")
+	}
+	if config.Dir == "" {
+		config.Dir = base.Dir
+		inherit("directory")
+	}
+	if config.Quality == 0 {
+		config.Quality = base.Quality
+		inherit("quality")
+	}
+	if config.Layout == "" {
+		config.Layout = base.Layout
+		inherit("layout")
+	}
+	if config.OutputTemplate == "" {
+		config.OutputTemplate = base.OutputTemplate
+		inherit("output-template")
+	}
+	if config.SubtitleTemplate == "" {
+		config.SubtitleTemplate = base.SubtitleTemplate
+		inherit("subtitle-template")
+	}
+	if config.Worker == 0 {
+		config.Worker = base.Worker
+		inherit("worker")
+	}
+	if config.RateLimit == "" {
+		config.RateLimit = base.RateLimit
+		inherit("rate-limit")
+	}
+	if config.CacheTTL == "" {
+		config.CacheTTL = base.CacheTTL
+		inherit("cache-ttl")
+	}
+	if config.Transcript == "" {
+		config.Transcript = base.Transcript
+		inherit("transcript")
+	}
+	if config.DualSubs == "" {
+		config.DualSubs = base.DualSubs
+		inherit("dual-subs")
+	}
+	if config.DualSubsFormat == "" {
+		config.DualSubsFormat = base.DualSubsFormat
+		inherit("dual-subs-format")
+	}
+	config.IsRefresh = config.IsRefresh || base.IsRefresh
+	config.IsOffline = config.IsOffline || base.IsOffline
+	config.IsAudioOnly = config.IsAudioOnly || base.IsAudioOnly
+	config.IsTranscriptTime = config.IsTranscriptTime || base.IsTranscriptTime
+	config.IsVerbose = config.IsVerbose || base.IsVerbose
+	config.Sources = sources
+	return config
+}
+
+// WithSource marks every non empty value of config as coming from source.
+func (config Config) WithSource(source string) Config {
+	config.Sources = make(map[string]string)
+	for key, value := range config.values() {
+		if value != "" {
+			config.Sources[key] = source
+		}
+	}
+	return config
+}
+
+func (config Config) values() map[string]string {
+	values := map[string]string{
+		"class":             config.UrlOrId,
+		"cookies":           utils.RedactCookies(config.Cookies),
+		"cookie-file":       config.CookieFile,
+		"vault":             config.Vault,
+		"vault-file":        config.VaultFile,
+		"language":          config.Lang,
+		"directory":         config.Dir,
+		"layout":            config.Layout,
+		"output-template":   config.OutputTemplate,
+		"subtitle-template": config.SubtitleTemplate,
+		"rate-limit":        config.RateLimit,
+		"cache-ttl":         config.CacheTTL,
+		"transcript":        config.Transcript,
+		"dual-subs":         config.DualSubs,
+		"dual-subs-format":  config.DualSubsFormat,
+	}
+	if config.Quality != 0 {
+		values["quality"] = strconv.Itoa(config.Quality)
+	}
+	if config.Worker != 0 {
+		values["worker"] = strconv.Itoa(config.Worker)
+	}
+	return values
+}
+
+func (config Config) logSources() {
+	values := config.values()
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if values[key] == "" {
+			continue
+		}
+
+		source, ok := config.Sources[key]
+		if !ok {
+			source = "unknown"
+		}
+		logger.Debugf("Config %s = %s (from %s)", key, values[key], source)
+	}
 }
 
 func (conf *AppConfig) parseID(config Config) error {
@@ -75,9 +270,16 @@ func (conf *AppConfig) parseID(config Config) error {
 	return errors.New("invalid class id or url")
 }
 
+// ParseClassID returns the class id of a class url or id.
+func ParseClassID(urlOrId string) (int, error) {
+	var conf AppConfig
+	err := conf.parseID(Config{UrlOrId: urlOrId})
+	return conf.ID, err
+}
+
 func (conf *AppConfig) parseCookies(config Config) error {
-	if config.Cookies == "" && config.CookieFile == "" {
-		return errors.New("cookies or cookie-file is required")
+	if config.Cookies == "" && config.CookieFile == "" && config.Vault == "" {
+		return errors.New("cookies, cookie-file or vault is required")
 	}
 
 	if config.Cookies != "" {
@@ -87,6 +289,17 @@ func (conf *AppConfig) parseCookies(config Config) error {
 		return nil
 	}
 
+	if config.CookieFile == "" {
+		logger.Debugf("Set cookies with vault account: %s", config.Vault)
+		cookie, err := loadVaultCookies(config.VaultFile, config.Vault)
+		if err != nil {
+			return err
+		}
+		conf.Cookies = cookie
+		logger.Info("Loaded vault cookies")
+		return nil
+	}
+
 	extension := filepath.Ext(config.CookieFile)
 	switch extension {
 	case ".txt":
@@ -103,6 +316,53 @@ func (conf *AppConfig) parseCookies(config Config) error {
 	}
 }
 
+// LoadCookies only load the cookies part of the config, so it can be used
+// without any class.
+func (conf *AppConfig) LoadCookies(config Config) error {
+	logger.Debug("Do parse cookies")
+	if err := conf.parseCookies(config); err != nil {
+		return err
+	}
+
+	if cookies, ok := utils.ParseNetscapeCookies(conf.Cookies); ok {
+		logger.Debug("Convert netscape cookies to header cookies")
+		cookies = utils.FilterCookies(cookies, constants.CookieDomain)
+		if len(cookies) == 0 {
+			return fmt.Errorf("no cookies of %s", constants.CookieDomain)
+		}
+
+		// the expiry is the session, the http only cookies, the cookies set
+		// by the scripts are analytics and expire on their own
+		conf.Cookies = utils.JoinCookies(cookies)
+		for _, cookie := range cookies {
+			if cookie.Expires.IsZero() || !cookie.IsHttpOnly {
+				continue
+			}
+			if conf.CookieExpiry.IsZero() || cookie.Expires.Before(conf.CookieExpiry) {
+				conf.CookieExpiry = cookie.Expires
+			}
+		}
+	}
+
+	logger.Debug("Clean Cookies")
+	conf.Cookies = utils.CleanCookies(conf.Cookies)
+	return nil
+}
+
+func loadVaultCookies(vaultFile, account string) (string, error) {
+	v, err := vault.Open(vaultFile)
+	if err != nil {
+		return "", err
+	}
+
+	passphrase, err := vault.Passphrase(false)
+	if err != nil {
+		return "", err
+	}
+
+	return v.Load(account, passphrase)
+}
+
 func (conf *AppConfig) parseLanguage(config Config) {
 	if config.Lang == "" && conf.Lang == "" {
 		logger.Debug("Set default language")
@@ -127,6 +387,63 @@ func (conf *AppConfig) parseDirectory(config Config) {
 	conf.Dir = config.Dir
 }
 
+func (conf *AppConfig) parseQuality(config Config) error {
+	if config.Quality < 0 {
+		return fmt.Errorf("invalid quality %d", config.Quality)
+	}
+
+	if config.Quality == 0 {
+		logger.Debug("Set best quality")
+	} else {
+		logger.Debugf("Set quality from config: %dp", config.Quality)
+	}
+	conf.Quality = config.Quality
+	return nil
+}
+
+func (conf *AppConfig) parseLayout(config Config) error {
+	switch config.Layout {
+	case "":
+		logger.Debug("Set default layout")
+		conf.Layout = constants.DefaultLayout
+		return nil
+	case constants.LayoutFlat, constants.LayoutUnits:
+		logger.Debug("Set layout from config")
+		conf.Layout = config.Layout
+		return nil
+	default:
+		return fmt.Errorf("invalid layout %s, use %s or %s", config.Layout, constants.LayoutFlat, constants.LayoutUnits)
+	}
+}
+
+func (conf *AppConfig) parseTemplate(config Config) error {
+	outputTemplate := config.OutputTemplate
+	if outputTemplate == "" {
+		logger.Debug("Set default output template")
+		outputTemplate = constants.DefaultOutputTemplate
+	}
+
+	tmpl, err := NewFilenameTemplate("output-template", outputTemplate)
+	if err != nil {
+		return err
+	}
+	conf.OutputTemplate = tmpl
+
+	subtitleTemplate := config.SubtitleTemplate
+	if subtitleTemplate == "" {
+		logger.Debug("Set default subtitle template")
+		subtitleTemplate = constants.DefaultSubtitleTemplate
+	}
+
+	tmpl, err = NewFilenameTemplate("subtitle-template", subtitleTemplate)
+	if err != nil {
+		return err
+	}
+	conf.SubtitleTemplate = tmpl
+
+	return nil
+}
+
 func (conf *AppConfig) parseWorker(config Config) {
 	if config.Worker == 0 {
 		logger.Debug("Set default worker")
@@ -135,7 +452,7 @@ func (conf *AppConfig) parseWorker(config Config) {
 	}
 
 	if config.Worker > constants.MaxWorker {
-		logger.Warningf("Worker large than %s", constants.MaxWorker)
+		logger.Warningf("Worker large than %d", constants.MaxWorker)
 		logger.Info("Set default worker")
 		conf.Worker = constants.DefaultWorker
 		return
@@ -145,19 +462,123 @@ func (conf *AppConfig) parseWorker(config Config) {
 	conf.Worker = config.Worker
 }
 
-func (conf *AppConfig) FromConfig(config Config) error {
-	logger.Debug("Do parse class id")
-	if err := conf.parseID(config); err != nil {
-		return err
+func (conf *AppConfig) parseRateLimit(config Config) error {
+	if config.RateLimit == "" {
+		logger.Debug("Set unlimited download rate")
+		conf.RateLimit = 0
+		return nil
 	}
 
-	logger.Debug("Do parse cookies")
-	if err := conf.parseCookies(config); err != nil {
+	rate, err := utils.ParseBytes(config.RateLimit)
+	if err != nil {
+		return fmt.Errorf("invalid rate limit %s: %w", config.RateLimit, err)
+	}
+
+	logger.Debugf("Set rate limit from config: %s/s", utils.FormatBytes(rate))
+	conf.RateLimit = rate
+	return nil
+}
+
+func (conf *AppConfig) parseCache(config Config) error {
+	if config.IsRefresh && config.IsOffline {
+		return errors.New("refresh and offline can not be used together")
+	}
+
+	conf.IsRefresh = config.IsRefresh
+	conf.IsOffline = config.IsOffline
+	if config.CacheTTL == "" {
+		logger.Debug("Set default cache ttl")
+		conf.CacheTTL = constants.DefaultCacheTTL
+		return nil
+	}
+
+	ttl, err := time.ParseDuration(config.CacheTTL)
+	if err != nil {
+		return fmt.Errorf("invalid cache ttl %s: %w", config.CacheTTL, err)
+	}
+
+	if ttl < 0 {
+		return fmt.Errorf("invalid cache ttl %s", config.CacheTTL)
+	}
+
+	logger.Debug("Set cache ttl from config")
+	conf.CacheTTL = ttl
+	return nil
+}
+
+func (conf *AppConfig) parseTranscript(config Config) error {
+	switch config.Transcript {
+	case "":
+		logger.Debug("Set no transcript")
+	case constants.TranscriptMarkdown, constants.TranscriptText:
+		logger.Debug("Set transcript from config")
+	default:
+		return fmt.Errorf("invalid transcript %s, use %s or %s", config.Transcript, constants.TranscriptMarkdown, constants.TranscriptText)
+	}
+
+	conf.Transcript = config.Transcript
+	conf.IsTranscriptTime = config.IsTranscriptTime
+	return nil
+}
+
+func (conf *AppConfig) parseDualSubs(config Config) error {
+	switch config.DualSubsFormat {
+	case "":
+		conf.DualSubsFormat = constants.DualSubsVTT
+	case constants.DualSubsVTT, constants.DualSubsASS:
+		conf.DualSubsFormat = config.DualSubsFormat
+	default:
+		return fmt.Errorf("invalid dual subtitles format %s, use %s or %s", config.DualSubsFormat, constants.DualSubsVTT, constants.DualSubsASS)
+	}
+
+	if config.DualSubs == "" {
+		logger.Debug("Set no dual subtitles")
+		conf.DualSubs = nil
+		return nil
+	}
+
+	langs := strings.Split(config.DualSubs, ",")
+	for idx := range langs {
+		langs[idx] = strings.TrimSpace(langs[idx])
+	}
+
+	if len(langs) != 2 || langs[0] == "" || langs[1] == "" || strings.EqualFold(langs[0], langs[1]) {
+		return fmt.Errorf("invalid dual subtitles %s, use two languages like en-US,es", config.DualSubs)
+	}
+
+	logger.Debugf("Set dual subtitles from config: %s on top of %s", langs[0], langs[1])
+	conf.DualSubs = langs
+	return nil
+}
+
+// MultiLangTemplate add the language before the extension of the subtitle
+// template when it has none, the subtitles of every language would be
+// written to one file.
+func (conf *AppConfig) MultiLangTemplate() error {
+	text := conf.SubtitleTemplate.Tree.Root.String()
+	if strings.Contains(text, ".Lang") {
+		return nil
+	}
+
+	idx := strings.LastIndex(text, "{{.Ext}}")
+	if idx < 0 {
+		return errors.New("subtitle template must have {{.Lang}} or {{.Ext}} to download more than one language")
+	}
+
+	text = text[:idx] + constants.SubtitleLangTemplate + text[idx:]
+	logger.Infof("Add the language to the subtitle template: %s", text)
+	tmpl, err := NewFilenameTemplate("subtitle-template", text)
+	if err != nil {
 		return err
 	}
+	conf.SubtitleTemplate = tmpl
+	return nil
+}
 
-	logger.Debug("Clean Cookies")
-	conf.Cookies = utils.CleanCookies(conf.Cookies)
+// LoadLocal only load the config needed to work with the downloaded classes,
+// without class id and cookies.
+func (conf *AppConfig) LoadLocal(config Config) error {
+	config.logSources()
 
 	logger.Debug("Do language")
 	conf.parseLanguage(config)
@@ -165,10 +586,70 @@ func (conf *AppConfig) FromConfig(config Config) error {
 	logger.Debug("Do directory")
 	conf.parseDirectory(config)
 
+	logger.Debug("Do quality")
+	if err := conf.parseQuality(config); err != nil {
+		return err
+	}
+
+	logger.Debug("Do layout")
+	if err := conf.parseLayout(config); err != nil {
+		return err
+	}
+
+	logger.Debug("Do template")
+	if err := conf.parseTemplate(config); err != nil {
+		return err
+	}
+
+	logger.Debug("Do transcript")
+	if err := conf.parseTranscript(config); err != nil {
+		return err
+	}
+
+	logger.Debug("Do dual subtitles")
+	if err := conf.parseDualSubs(config); err != nil {
+		return err
+	}
+
+	// the local commands must find the subtitles where the download wrote them
+	if len(conf.DualSubs) > 0 {
+		if err := conf.MultiLangTemplate(); err != nil {
+			return err
+		}
+	}
+
+	conf.IsAudioOnly = config.IsAudioOnly
+	conf.IsVerbose = config.IsVerbose
+	return nil
+}
+
+func (conf *AppConfig) FromConfig(config Config) error {
+	logger.Debug("Do parse class id")
+	if err := conf.parseID(config); err != nil {
+		return err
+	}
+
+	logger.Debug("Do load cookies")
+	if err := conf.LoadCookies(config); err != nil {
+		return err
+	}
+
+	if err := conf.LoadLocal(config); err != nil {
+		return err
+	}
+
 	logger.Debug("Do worker")
 	conf.parseWorker(config)
 
-	conf.IsVerbose = config.IsVerbose
+	logger.Debug("Do rate limit")
+	if err := conf.parseRateLimit(config); err != nil {
+		return err
+	}
+
+	logger.Debug("Do cache")
+	if err := conf.parseCache(config); err != nil {
+		return err
+	}
 
 	return nil
 }
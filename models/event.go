@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+const (
+	EventStart    = "start"
+	EventStep     = "step"
+	EventProgress = "progress"
+	EventDone     = "done"
+	EventError    = "error"
+)
+
+const (
+	StageClass    = "class"
+	StageMetadata = "metadata"
+	StageVideo    = "video"
+	StageSubtitle = "subtitle"
+)
+
+// Event is reported by the download pipeline. A stage is started with the
+// count of items in Total, every finished item is a step with Current, and
+// the byte progress of a lesson is only reported on the video stage.
+type Event struct {
+	Type     string    `json:"type"`
+	Stage    string    `json:"stage"`
+	ClassID  int       `json:"class_id"`
+	Current  int       `json:"current,omitempty"`
+	Total    int       `json:"total,omitempty"`
+	Lesson   string    `json:"lesson,omitempty"`
+	Message  string    `json:"message,omitempty"`
+	Progress *Progress `json:"progress,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	Time     time.Time `json:"time"`
+}
@@ -0,0 +1,9 @@
+package models
+
+type FeedOptions struct {
+	// BaseURL is the url of the download root where the media are served.
+	BaseURL string
+	// IsLibrary write one feed of every class in the download root instead
+	// of one feed in every class directory.
+	IsLibrary bool
+}
@@ -0,0 +1,67 @@
+package models
+
+import "strings"
+
+// ClassFiles are the files written for the lessons of a class by video id,
+// the paths are relative to the class directory. They are read back instead
+// of rendering the templates again, the config may change after the
+// download.
+type ClassFiles struct {
+	Lessons map[int]*LessonFiles `json:"lessons"`
+}
+
+type LessonFiles struct {
+	Data        string            `json:"data,omitempty"`
+	Video       string            `json:"video,omitempty"`
+	Height      int               `json:"height,omitempty"`
+	IsAudioOnly bool              `json:"is_audio_only,omitempty"`
+	Subtitles   map[string]string `json:"subtitles,omitempty"`
+	Dual        map[string]string `json:"dual,omitempty"`
+}
+
+// Lesson returns the files of the lesson, nil when nothing is written.
+func (cf *ClassFiles) Lesson(videoID int) *LessonFiles {
+	if cf == nil {
+		return nil
+	}
+	return cf.Lessons[videoID]
+}
+
+// Update the files of the lesson, the lesson is added when missing.
+func (cf *ClassFiles) Update(videoID int, fn func(lesson *LessonFiles)) {
+	if cf.Lessons == nil {
+		cf.Lessons = make(map[int]*LessonFiles)
+	}
+
+	lesson := cf.Lessons[videoID]
+	if lesson == nil {
+		lesson = &LessonFiles{}
+		cf.Lessons[videoID] = lesson
+	}
+	fn(lesson)
+}
+
+// Subtitle returns the subtitle of the language, the language is not case
+// sensitive.
+func (lf *LessonFiles) Subtitle(lang string) (string, bool) {
+	if lf == nil {
+		return "", false
+	}
+	filePath, ok := lf.Subtitles[strings.ToLower(lang)]
+	return filePath, ok
+}
+
+func (lf *LessonFiles) SetSubtitle(lang, filePath string) {
+	if lf.Subtitles == nil {
+		lf.Subtitles = make(map[string]string)
+	}
+	lf.Subtitles[strings.ToLower(lang)] = filePath
+}
+
+// SetDual set the dual subtitles with the secondary language.
+func (lf *LessonFiles) SetDual(lang, filePath string) {
+	if lf.Dual == nil {
+		lf.Dual = make(map[string]string)
+	}
+	lf.Dual[strings.ToLower(lang)] = filePath
+}
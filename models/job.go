@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobDone      = "done"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+type Job struct {
+	ID         string    `json:"id"`
+	Class      string    `json:"class"`
+	ClassID    int       `json:"class_id"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	Progress   Progress  `json:"progress"`
+	CreatedAt  time.Time `json:"created_at"`
+	StartedAt  time.Time `json:"started_at,omitempty"`
+	FinishedAt time.Time `json:"finished_at,omitempty"`
+}
+
+func (j *Job) IsFinished() bool {
+	return j.Status == JobDone || j.Status == JobFailed || j.Status == JobCancelled
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+type LibraryClass struct {
+	ID           int             `json:"id"`
+	Title        string          `json:"title"`
+	Teacher      string          `json:"teacher"`
+	Category     string          `json:"category"`
+	Dir          string          `json:"dir"`
+	Languages    []string        `json:"languages"`
+	Size         int64           `json:"size"`
+	Lessons      []LibraryLesson `json:"lessons"`
+	DownloadedAt time.Time       `json:"downloaded_at"`
+	IndexedAt    time.Time       `json:"indexed_at"`
+}
+
+type LibraryLesson struct {
+	Index        int               `json:"index"`
+	ID           int               `json:"id"`
+	Title        string            `json:"title"`
+	Unit         string            `json:"unit"`
+	Duration     string            `json:"duration"`
+	Path         string            `json:"path"`
+	Size         int64             `json:"size"`
+	Subtitles    []LibrarySubtitle `json:"subtitles"`
+	DownloadedAt time.Time         `json:"downloaded_at"`
+}
+
+type LibrarySubtitle struct {
+	Lang string `json:"lang"`
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// Match check the text in the title, teacher, category and lesson titles.
+func (lc *LibraryClass) Match(text string) bool {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if text == "" {
+		return true
+	}
+
+	fields := []string{lc.Title, lc.Teacher, lc.Category}
+	for _, lesson := range lc.Lessons {
+		fields = append(fields, lesson.Title, lesson.Unit)
+	}
+
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), text) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (lc *LibraryClass) CountDownloaded() int {
+	count := 0
+	for _, lesson := range lc.Lessons {
+		if lesson.Path != "" {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+	"github.com/rizalarfiyan/skillshare-downloader/logger"
+	"github.com/rizalarfiyan/skillshare-downloader/utils"
+	"gopkg.in/yaml.v3"
+)
+
+type FileConfig struct {
+	Profile `yaml:",inline"`
+
+	DefaultProfile string             `yaml:"default_profile,omitempty"`
+	Profiles       map[string]Profile `yaml:"profiles,omitempty"`
+}
+
+type Profile struct {
+	Cookies          string `yaml:"cookies,omitempty"`
+	CookieFile       string `yaml:"cookie_file,omitempty"`
+	Vault            string `yaml:"vault,omitempty"`
+	VaultFile        string `yaml:"vault_file,omitempty"`
+	Directory        string `yaml:"directory,omitempty"`
+	Quality          int    `yaml:"quality,omitempty"`
+	Language         string `yaml:"language,omitempty"`
+	Layout           string `yaml:"layout,omitempty"`
+	OutputTemplate   string `yaml:"output_template,omitempty"`
+	SubtitleTemplate string `yaml:"subtitle_template,omitempty"`
+	Worker           int    `yaml:"worker,omitempty"`
+	RateLimit        string `yaml:"rate_limit,omitempty"`
+	CacheTTL         string `yaml:"cache_ttl,omitempty"`
+	Transcript       string `yaml:"transcript,omitempty"`
+	DualSubs         string `yaml:"dual_subs,omitempty"`
+	DualSubsFormat   string `yaml:"dual_subs_format,omitempty"`
+}
+
+func DefaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, constants.ConfigDirName, constants.ConfigFileName)
+}
+
+func LoadFileConfig(pathfile string) (*FileConfig, error) {
+	conf := &FileConfig{}
+	isDefault := pathfile == ""
+	if isDefault {
+		pathfile = DefaultConfigPath()
+	}
+
+	if pathfile == "" || (isDefault && !utils.IsExistPath(pathfile)) {
+		logger.Debug("No config file found, use empty config")
+		return conf, nil
+	}
+
+	logger.Debugf("Load config file: %s", pathfile)
+	data, err := os.ReadFile(pathfile)
+	if err != nil {
+		return nil, err
+	}
+
+	err = yaml.Unmarshal(data, conf)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", pathfile, err)
+	}
+
+	return conf, nil
+}
+
+func (fc *FileConfig) Config(name string) (Config, error) {
+	if name == "" {
+		name = fc.DefaultProfile
+	}
+
+	config := fc.Profile.Config().WithSource("config file")
+	if name != "" {
+		named, ok := fc.Profiles[name]
+		if !ok {
+			return Config{}, fmt.Errorf("profile %s not found in config file", name)
+		}
+
+		logger.Debugf("Use profile: %s", name)
+		config = named.Config().WithSource(fmt.Sprintf("profile %s", name)).Merge(config)
+	}
+
+	return config, nil
+}
+
+func (p Profile) Config() Config {
+	return Config{
+		Cookies:          p.Cookies,
+		CookieFile:       p.CookieFile,
+		Vault:            p.Vault,
+		VaultFile:        p.VaultFile,
+		Dir:              p.Directory,
+		Quality:          p.Quality,
+		Lang:             p.Language,
+		Layout:           p.Layout,
+		OutputTemplate:   p.OutputTemplate,
+		SubtitleTemplate: p.SubtitleTemplate,
+		Worker:           p.Worker,
+		RateLimit:        p.RateLimit,
+		CacheTTL:         p.CacheTTL,
+		Transcript:       p.Transcript,
+		DualSubs:         p.DualSubs,
+		DualSubsFormat:   p.DualSubsFormat,
+	}
+}
+
+func ProfileFromConfig(config Config) Profile {
+	return Profile{
+		Cookies:          config.Cookies,
+		CookieFile:       config.CookieFile,
+		Vault:            config.Vault,
+		VaultFile:        config.VaultFile,
+		Directory:        config.Dir,
+		Quality:          config.Quality,
+		Language:         config.Lang,
+		Layout:           config.Layout,
+		OutputTemplate:   config.OutputTemplate,
+		SubtitleTemplate: config.SubtitleTemplate,
+		Worker:           config.Worker,
+		RateLimit:        config.RateLimit,
+		CacheTTL:         config.CacheTTL,
+		Transcript:       config.Transcript,
+		DualSubs:         config.DualSubs,
+		DualSubsFormat:   config.DualSubsFormat,
+	}
+}
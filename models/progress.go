@@ -0,0 +1,15 @@
+package models
+
+// Progress is the download state of the current lesson, Speed is in bytes
+// per second.
+type Progress struct {
+	ClassID    int    `json:"class_id"`
+	ClassTitle string `json:"class_title"`
+	Index      int    `json:"index"`
+	Total      int    `json:"total"`
+	Lesson     string `json:"lesson"`
+	Bytes      int64  `json:"bytes"`
+	TotalBytes int64  `json:"total_bytes"`
+	Speed      int64  `json:"speed"`
+	Done       bool   `json:"done"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// QueueClass is the persisted download state of a class, the status use the
+// same values as Job.
+type QueueClass struct {
+	ClassID   int           `json:"class_id"`
+	Class     string        `json:"class"`
+	Title     string        `json:"title"`
+	Status    string        `json:"status"`
+	Attempts  int           `json:"attempts"`
+	LastError string        `json:"last_error,omitempty"`
+	Lessons   []QueueLesson `json:"lessons"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+type QueueLesson struct {
+	Index      int       `json:"index"`
+	ID         int       `json:"id"`
+	Title      string    `json:"title"`
+	Status     string    `json:"status"`
+	Attempts   int       `json:"attempts"`
+	LastError  string    `json:"last_error,omitempty"`
+	BytesDone  int64     `json:"bytes_done"`
+	TotalBytes int64     `json:"total_bytes"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (qc *QueueClass) IsFinished() bool {
+	return qc.Status == JobDone || qc.Status == JobFailed || qc.Status == JobCancelled
+}
+
+// Lesson returns the lesson with the video id, it is added when missing.
+func (qc *QueueClass) Lesson(index, id int, title string) *QueueLesson {
+	for idx := range qc.Lessons {
+		if qc.Lessons[idx].ID == id {
+			qc.Lessons[idx].Index = index
+			qc.Lessons[idx].Title = title
+			return &qc.Lessons[idx]
+		}
+	}
+
+	qc.Lessons = append(qc.Lessons, QueueLesson{
+		Index:  index,
+		ID:     id,
+		Title:  title,
+		Status: JobQueued,
+	})
+	return &qc.Lessons[len(qc.Lessons)-1]
+}
+
+func (qc *QueueClass) BytesDone() int64 {
+	var total int64
+	for _, lesson := range qc.Lessons {
+		total += lesson.BytesDone
+	}
+	return total
+}
+
+func (qc *QueueClass) CountStatus(status string) int {
+	count := 0
+	for _, lesson := range qc.Lessons {
+		if lesson.Status == status {
+			count++
+		}
+	}
+	return count
+}
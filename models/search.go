@@ -0,0 +1,56 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// SearchDoc is an indexed subtitle file, the paths are relative to the
+// download root.
+type SearchDoc struct {
+	Path       string      `json:"path"`
+	Video      string      `json:"video"`
+	ClassID    int         `json:"class_id"`
+	ClassTitle string      `json:"class_title"`
+	Index      int         `json:"index"`
+	Lesson     string      `json:"lesson"`
+	Lang       string      `json:"lang"`
+	Size       int64       `json:"size"`
+	ModTime    time.Time   `json:"mod_time"`
+	Cues       []SearchCue `json:"cues"`
+}
+
+type SearchCue struct {
+	Start time.Duration `json:"start"`
+	End   time.Duration `json:"end"`
+	Text  string        `json:"text"`
+}
+
+type SearchResult struct {
+	ClassID    int           `json:"class_id"`
+	ClassTitle string        `json:"class_title"`
+	Index      int           `json:"index"`
+	Lesson     string        `json:"lesson"`
+	Lang       string        `json:"lang"`
+	Path       string        `json:"path"`
+	Video      string        `json:"video"`
+	Start      time.Duration `json:"start"`
+	Timestamp  string        `json:"timestamp"`
+	Context    string        `json:"context"`
+}
+
+// Reference returns file:timestamp of the match, the video when it is
+// downloaded and the subtitle otherwise.
+func (sr SearchResult) Reference() string {
+	file := sr.Video
+	if file == "" {
+		file = sr.Path
+	}
+	return fmt.Sprintf("%s:%s", file, sr.Timestamp)
+}
+
+type SearchStats struct {
+	Indexed int `json:"indexed"`
+	Removed int `json:"removed"`
+	Total   int `json:"total"`
+}
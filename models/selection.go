@@ -0,0 +1,9 @@
+package models
+
+// Selection is picked by the user after the class data is loaded, Quality 0
+// is the best rendition and nil Languages keep the configured language.
+type Selection struct {
+	Lessons   []int
+	Quality   int
+	Languages []string
+}
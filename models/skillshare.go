@@ -14,6 +14,7 @@ type ClassData struct {
 	Gid                        string `json:"gid"`
 	Sku                        int    `json:"sku"`
 	Title                      string `json:"title"`
+	Description                any    `json:"description"`
 	ProjectTitle               string `json:"project_title"`
 	ImageHuge                  string `json:"image_huge"`
 	ImageSmall                 string `json:"image_small"`
@@ -78,7 +79,14 @@ type ClassData struct {
 				Wishlist      ClassDataLink `json:"wishlist"`
 			} `json:"_links"`
 		} `json:"teacher"`
-		Units    any `json:"units"`
+		Units struct {
+			Links struct {
+				Self ClassDataLink `json:"self"`
+			} `json:"_links"`
+			Embedded struct {
+				Units []ClassDataUnit `json:"units"`
+			} `json:"_embedded"`
+		} `json:"units"`
 		Sessions struct {
 			Links struct {
 				Self ClassDataLink `json:"self"`
@@ -115,11 +123,31 @@ type ClassData struct {
 	} `json:"_embedded"`
 }
 
+type CacheMeta struct {
+	ClassID   int       `json:"class_id"`
+	FetchedAt time.Time `json:"fetched_at"`
+	Version   string    `json:"version"`
+}
+
 type ClassDataLink struct {
 	Href  string `json:"href"`
 	Title string `json:"title"`
 }
 
+type ClassDataUnit struct {
+	ID             int    `json:"id"`
+	ParentClassSku int    `json:"parent_class_sku"`
+	Title          string `json:"title"`
+	Rank           int    `json:"rank"`
+	CreateTime     string `json:"create_time"`
+	UpdateTime     string `json:"update_time"`
+	Links          struct {
+		Self        ClassDataLink `json:"self"`
+		ParentClass ClassDataLink `json:"parentClass"`
+		Sessions    ClassDataLink `json:"sessions"`
+	} `json:"_links"`
+}
+
 func (cd *ClassData) IsValidVideoId() bool {
 	if len(cd.Embedded.Sessions.Embedded.Sessions) == 0 {
 		return false
@@ -137,20 +165,36 @@ func (cd *ClassData) IsValidVideoId() bool {
 type SkillshareClass struct {
 	ID                         int               `json:"id"`
 	Title                      string            `json:"title"`
+	Description                string            `json:"description"`
 	ProjectTitle               string            `json:"project_title"`
+	Teacher                    string            `json:"teacher"`
 	Category                   string            `json:"category"`
 	TotalVideosDuration        string            `json:"total_videos_duration"`
 	TotalVideosDurationSeconds int               `json:"total_videos_duration_seconds"`
 	ImageHuge                  string            `json:"image_huge"`
 	ImageSmall                 string            `json:"image_small"`
 	ImageThumbnail             string            `json:"image_thumbnail"`
+	Units                      []SkillshareUnit  `json:"units"`
 	Videos                     []SkillshareVideo `json:"videos"`
 }
 
+type SkillshareUnit struct {
+	ID     int    `json:"id"`
+	Title  string `json:"title"`
+	Rank   int    `json:"rank"`
+	Number int    `json:"number"`
+}
+
 type SkillshareVideo struct {
 	ID                   int                       `json:"id"`
+	SessionID            int                       `json:"session_id"`
 	Title                string                    `json:"title"`
 	VideoID              string                    `json:"video_id"`
+	UpdateTime           string                    `json:"update_time"`
+	Rank                 int                       `json:"rank"`
+	UnitID               int                       `json:"unit_id"`
+	UnitTitle            string                    `json:"unit_title"`
+	UnitNumber           int                       `json:"unit_number"`
 	VideoDuration        string                    `json:"video_duration"`
 	VideoDurationSeconds int                       `json:"video_duration_seconds"`
 	VideoThumbnailURL    string                    `json:"video_thumbnail_url"`
@@ -181,7 +225,9 @@ func (cd *ClassData) Mapper() SkillshareClass {
 	ssData := SkillshareClass{
 		ID:                         cd.ID,
 		Title:                      utils.DecodeAscii(cd.Title),
+		Description:                utils.DecodeAscii(TextValue(cd.Description)),
 		ProjectTitle:               cd.ProjectTitle,
+		Teacher:                    utils.DecodeAscii(cd.Embedded.Teacher.FullName),
 		Category:                   cd.Category,
 		TotalVideosDuration:        cd.TotalVideosDuration,
 		TotalVideosDurationSeconds: cd.TotalVideosDurationSeconds,
@@ -190,7 +236,35 @@ func (cd *ClassData) Mapper() SkillshareClass {
 		ImageThumbnail:             cd.ImageThumbnail,
 	}
 
-	for _, session := range cd.Embedded.Sessions.Embedded.Sessions {
+	units := make([]ClassDataUnit, len(cd.Embedded.Units.Embedded.Units))
+	copy(units, cd.Embedded.Units.Embedded.Units)
+	sort.SliceStable(units, func(i, j int) bool {
+		return units[i].Rank < units[j].Rank
+	})
+
+	mapUnit := make(map[int]SkillshareUnit)
+	for idx, unit := range units {
+		ssUnit := SkillshareUnit{
+			ID:     unit.ID,
+			Title:  utils.DecodeAscii(unit.Title),
+			Rank:   unit.Rank,
+			Number: idx + 1,
+		}
+		mapUnit[unit.ID] = ssUnit
+		ssData.Units = append(ssData.Units, ssUnit)
+	}
+
+	sessions := cd.Embedded.Sessions.Embedded.Sessions
+	sessions = append(sessions[:0:0], sessions...)
+	sort.SliceStable(sessions, func(i, j int) bool {
+		unitI, unitJ := mapUnit[sessions[i].UnitID], mapUnit[sessions[j].UnitID]
+		if unitI.Number != unitJ.Number {
+			return unitI.Number < unitJ.Number
+		}
+		return sessions[i].Rank < sessions[j].Rank
+	})
+
+	for _, session := range sessions {
 		var videoId int
 		videoArr := strings.Split(session.VideoHashedID, ":")
 		if len(videoArr) > 1 {
@@ -199,8 +273,14 @@ func (cd *ClassData) Mapper() SkillshareClass {
 
 		ssData.Videos = append(ssData.Videos, SkillshareVideo{
 			ID:                   videoId,
+			SessionID:            session.ID,
 			Title:                utils.DecodeAscii(session.Title),
 			VideoID:              session.VideoHashedID,
+			UpdateTime:           session.UpdateTime,
+			Rank:                 session.Rank,
+			UnitID:               session.UnitID,
+			UnitTitle:            mapUnit[session.UnitID].Title,
+			UnitNumber:           mapUnit[session.UnitID].Number,
 			VideoDuration:        session.VideoDuration,
 			VideoDurationSeconds: session.VideoDurationSeconds,
 			VideoThumbnailURL:    session.VideoThumbnailURL,
@@ -255,6 +335,59 @@ func (sc *SkillshareVideo) AddSourceSubtitle(video VideoData) {
 	sc.Subtitles = subtitles
 }
 
+// SelectSource picks the best source with height not larger than quality,
+// zero quality means the best available source.
+func (sc *SkillshareVideo) SelectSource(quality int) (SkillshareVideoSource, bool) {
+	var selected *SkillshareVideoSource
+	var fallback *SkillshareVideoSource
+	for idx := range sc.Sources {
+		source := &sc.Sources[idx]
+		if fallback == nil || source.Height < fallback.Height {
+			fallback = source
+		}
+
+		if quality > 0 && source.Height > quality {
+			continue
+		}
+
+		if selected == nil || source.Height > selected.Height || (source.Height == selected.Height && source.AvgBitrate > selected.AvgBitrate) {
+			selected = source
+		}
+	}
+
+	if selected == nil {
+		selected = fallback
+	}
+
+	if selected == nil {
+		return SkillshareVideoSource{}, false
+	}
+
+	return *selected, true
+}
+
+// SelectAudioSource picks the source with the lowest bitrate, every mp4
+// rendition carries the same audio track so it is the smallest download.
+func (sc *SkillshareVideo) SelectAudioSource() (SkillshareVideoSource, bool) {
+	var selected *SkillshareVideoSource
+	for idx := range sc.Sources {
+		source := &sc.Sources[idx]
+		if source.Container != "" && !strings.EqualFold(source.Container, "MP4") {
+			continue
+		}
+
+		if selected == nil || source.AvgBitrate < selected.AvgBitrate || (source.AvgBitrate == selected.AvgBitrate && source.Height < selected.Height) {
+			selected = source
+		}
+	}
+
+	if selected == nil {
+		return SkillshareVideoSource{}, false
+	}
+
+	return *selected, true
+}
+
 type VideoData struct {
 	Poster           string            `json:"poster"`
 	Thumbnail        string            `json:"thumbnail"`
@@ -306,6 +439,21 @@ type VideoData struct {
 	AdKeys         any       `json:"ad_keys"`
 }
 
+// IsExpired check the signed url of the downloadable sources.
+func (vd *VideoData) IsExpired(margin time.Duration) bool {
+	for _, source := range vd.Sources {
+		if source.Codecs == "avc1,mp4a" || source.Codec == "" {
+			continue
+		}
+
+		if utils.IsSignedURLExpired(source.Src, margin) {
+			return true
+		}
+	}
+
+	return false
+}
+
 type VideoDataSource struct {
 	Src string `json:"src"`
 }
@@ -323,6 +471,7 @@ type SubtitleWorker struct {
 	SkillshareVideoSubtitle
 
 	Title   string
+	Path    string
 	Idx     int
 	VideoId int
 	Error   error
@@ -332,3 +481,39 @@ type Checklang struct {
 	IsFalid bool
 	Lang    string
 }
+
+type UserData struct {
+	ID        int    `json:"id"`
+	Username  any    `json:"username"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	FullName  string `json:"full_name"`
+	Email     string `json:"email"`
+	IsMember  bool   `json:"is_member"`
+	IsTeacher bool   `json:"is_teacher"`
+}
+
+type AuthStatus struct {
+	IsLoggedIn   bool
+	IsPremium    bool
+	UserID       int
+	Account      string
+	CookieExpiry time.Time
+}
+
+func (as *AuthStatus) IsExpired() bool {
+	return !as.CookieExpiry.IsZero() && as.CookieExpiry.Before(time.Now())
+}
+
+func (as *AuthStatus) IsUsable() bool {
+	return as.IsLoggedIn && as.IsPremium && !as.IsExpired()
+}
+
+// TextValue returns the value when it is a string, the api send null or an
+// object for some optional texts.
+func TextValue(value any) string {
+	if text, ok := value.(string); ok {
+		return strings.TrimSpace(text)
+	}
+	return ""
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+const (
+	SyncAdded   = "added"
+	SyncUpdated = "updated"
+	SyncRemoved = "removed"
+	SyncMoved   = "moved"
+)
+
+// SyncChange is a lesson changed since the cached class data, Index is 0 for
+// a removed lesson and OldIndex is 0 for an added lesson.
+type SyncChange struct {
+	Kind      string `json:"kind"`
+	SessionID int    `json:"session_id"`
+	VideoID   int    `json:"video_id"`
+	Title     string `json:"title"`
+	Index     int    `json:"index"`
+	OldIndex  int    `json:"old_index"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// SyncLog is one entry of the change log of a class.
+type SyncLog struct {
+	ClassID  int          `json:"class_id"`
+	Title    string       `json:"title"`
+	SyncedAt time.Time    `json:"synced_at"`
+	Version  string       `json:"version"`
+	Archive  string       `json:"archive,omitempty"`
+	Changes  []SyncChange `json:"changes"`
+}
+
+func (sl *SyncLog) Count(kind string) int {
+	count := 0
+	for _, change := range sl.Changes {
+		if change.Kind == kind {
+			count++
+		}
+	}
+	return count
+}
+
+// Downloads returns the video id of the added and updated lessons.
+func (sl *SyncLog) Downloads() map[int]bool {
+	videos := make(map[int]bool)
+	for _, change := range sl.Changes {
+		if change.Kind == SyncAdded || change.Kind == SyncUpdated {
+			videos[change.VideoID] = true
+		}
+	}
+	return videos
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/rizalarfiyan/skillshare-downloader/utils"
+)
+
+type TemplateData struct {
+	Class    TemplateClass
+	Unit     TemplateUnit
+	Lesson   TemplateLesson
+	Teacher  string
+	Category string
+	Index    int
+	Height   int
+	Lang     string
+	Ext      string
+}
+
+type TemplateClass struct {
+	ID    int
+	Title string
+}
+
+type TemplateUnit struct {
+	Number int
+	Title  string
+}
+
+type TemplateLesson struct {
+	ID    int
+	Title string
+}
+
+var templateFuncs = template.FuncMap{
+	"safe":  utils.SafeName,
+	"snake": utils.ToSnakeCase,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"trim":  strings.TrimSpace,
+	"pad": func(value, width int) string {
+		return fmt.Sprintf("%0*d", width, value)
+	},
+}
+
+var templateSample = TemplateData{
+	Class:    TemplateClass{ID: 123456789, Title: "Sample Class"},
+	Unit:     TemplateUnit{Number: 1, Title: "Sample Unit"},
+	Lesson:   TemplateLesson{ID: 987654321, Title: "Sample Lesson"},
+	Teacher:  "Sample Teacher",
+	Category: "Sample Category",
+	Index:    1,
+	Height:   720,
+	Lang:     "en-US",
+	Ext:      ".mp4",
+}
+
+func NewFilenameTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", name, err)
+	}
+
+	if _, err := RenderFilename(tmpl, templateSample); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", name, err)
+	}
+
+	return tmpl, nil
+}
+
+func RenderFilename(tmpl *template.Template, data TemplateData) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	filename := strings.TrimSpace(buf.String())
+	if filename == "" {
+		return "", errors.New("filename is empty")
+	}
+
+	filename = path.Clean(strings.ReplaceAll(filename, "\\", "/"))
+	if path.IsAbs(filename) || filename == ".." || strings.HasPrefix(filename, "../") {
+		return "", fmt.Errorf("filename %s is outside the class directory", filename)
+	}
+
+	var parts []string
+	for _, part := range strings.Split(filename, "/") {
+		if part = utils.SafeFilename(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	if len(parts) == 0 {
+		return "", errors.New("filename is empty")
+	}
+	return path.Join(parts...), nil
+}
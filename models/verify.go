@@ -0,0 +1,33 @@
+package models
+
+const (
+	IssueMissingData     = "missing_data"
+	IssueMissingVideo    = "missing_video"
+	IssueSizeMismatch    = "size_mismatch"
+	IssuePartFile        = "part_file"
+	IssueMissingSubtitle = "missing_subtitle"
+	IssueInvalidVideo    = "invalid_video"
+)
+
+type VerifyClass struct {
+	ID      int           `json:"id"`
+	Title   string        `json:"title"`
+	Dir     string        `json:"dir"`
+	Lessons int           `json:"lessons"`
+	Issues  []VerifyIssue `json:"issues"`
+}
+
+// VerifyIssue is a problem found in the class directory, Index is 0 when the
+// issue is not about a lesson.
+type VerifyIssue struct {
+	Index    int    `json:"index"`
+	LessonID int    `json:"lesson_id"`
+	Lesson   string `json:"lesson"`
+	Kind     string `json:"kind"`
+	Path     string `json:"path"`
+	Detail   string `json:"detail"`
+}
+
+func (vc *VerifyClass) IsValid() bool {
+	return len(vc.Issues) == 0
+}
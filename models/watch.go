@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+type WatchOptions struct {
+	ClassesFile string
+	Interval    time.Duration
+	Once        bool
+}
+
+// WatchState is persisted between the cycles, Failures and BackoffUntil are
+// for the auth and rate limit errors which stop the whole cycle.
+type WatchState struct {
+	Cycle        int                 `json:"cycle"`
+	LastCycle    time.Time           `json:"last_cycle"`
+	Failures     int                 `json:"failures"`
+	BackoffUntil time.Time           `json:"backoff_until"`
+	Classes      map[int]*WatchClass `json:"classes"`
+}
+
+type WatchClass struct {
+	LastSync  time.Time `json:"last_sync"`
+	LastError string    `json:"last_error,omitempty"`
+	Failures  int       `json:"failures"`
+	NextRun   time.Time `json:"next_run"`
+}
+
+type WatchSummary struct {
+	Synced  int
+	Skipped int
+	Failed  int
+	Added   int
+	Updated int
+	Removed int
+	Moved   int
+}
+
+func (ws *WatchSummary) Add(syncLog *SyncLog) {
+	ws.Synced++
+	ws.Added += syncLog.Count(SyncAdded)
+	ws.Updated += syncLog.Count(SyncUpdated)
+	ws.Removed += syncLog.Count(SyncRemoved)
+	ws.Moved += syncLog.Count(SyncMoved)
+}
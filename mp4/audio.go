@@ -0,0 +1,284 @@
+package mp4
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+var ErrNoAudio = errors.New("mp4 has no audio track")
+
+// Tags are written as iTunes metadata of the m4a.
+type Tags struct {
+	Title      string
+	Album      string
+	Artist     string
+	Track      int
+	TrackTotal int
+}
+
+// chunk is the position of a chunk of samples in the source file.
+type chunk struct {
+	offset int64
+	size   int64
+}
+
+// ExtractAudio write the audio track of the mp4 as a m4a with the tags, the
+// chunks of samples are copied without decoding.
+func ExtractAudio(r io.ReaderAt, size int64, w io.Writer, tags Tags) error {
+	boxes, err := ReadBoxes(r, 0, size)
+	if err != nil {
+		return err
+	}
+
+	moovBox, ok := Find(boxes, "moov")
+	if !ok {
+		return ErrNoMoov
+	}
+
+	moov, err := ReadNode(r, moovBox)
+	if err != nil {
+		return err
+	}
+
+	trak := audioTrak(moov)
+	if trak == nil {
+		return ErrNoAudio
+	}
+
+	stbl := trak.Path("mdia", "minf", "stbl")
+	if stbl == nil {
+		return fmt.Errorf("%w: no sample table", ErrNoAudio)
+	}
+
+	chunks, err := readChunks(stbl)
+	if err != nil {
+		return err
+	}
+
+	var mdatSize int64
+	for _, c := range chunks {
+		mdatSize += c.size
+	}
+	if mdatSize+8 > math.MaxUint32 {
+		return fmt.Errorf("audio track of %d bytes is too large", mdatSize)
+	}
+
+	mvhd := moov.Child("mvhd")
+	if mvhd == nil {
+		return fmt.Errorf("%w: no mvhd box", ErrNoMoov)
+	}
+
+	ftyp := NewNode("ftyp", []byte("M4A \x00\x00\x02\x00M4A mp42isom"))
+	newMoov := NewNode("moov", nil, mvhd, trak, NewNode("udta", nil, metaNode(tags)))
+
+	// the offsets of the chunks depend on the size of moov, the size of
+	// stco does not change with the values
+	setChunkOffsets(stbl, chunks, 0)
+	start := ftyp.Size() + newMoov.Size() + 8
+	setChunkOffsets(stbl, chunks, start)
+
+	if _, err := w.Write(ftyp.Bytes()); err != nil {
+		return err
+	}
+	if _, err := w.Write(newMoov.Bytes()); err != nil {
+		return err
+	}
+
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header, uint32(mdatSize+8))
+	copy(header[4:], "mdat")
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	for _, c := range chunks {
+		if _, err := io.Copy(w, io.NewSectionReader(r, c.offset, c.size)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ExtractAudioFile is ExtractAudio from the src file to the dst file, dst is
+// removed when the extraction fails.
+func ExtractAudioFile(src, dst string, tags Tags) error {
+	file, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	err = ExtractAudio(file, info.Size(), out, tags)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return nil
+}
+
+func audioTrak(moov *Node) *Node {
+	for _, trak := range moov.Children {
+		if trak.Type != "trak" {
+			continue
+		}
+
+		hdlr := trak.Path("mdia", "hdlr")
+		if hdlr != nil && len(hdlr.Data) >= 12 && string(hdlr.Data[8:12]) == "soun" {
+			return trak
+		}
+	}
+	return nil
+}
+
+// readChunks returns the chunks of the sample table with the size from the
+// sample sizes of every chunk.
+func readChunks(stbl *Node) ([]chunk, error) {
+	offsets, err := readChunkOffsets(stbl)
+	if err != nil {
+		return nil, err
+	}
+
+	stsz := stbl.Child("stsz")
+	stsc := stbl.Child("stsc")
+	if stsz == nil || stsc == nil || len(stsz.Data) < 12 || len(stsc.Data) < 8 {
+		return nil, fmt.Errorf("%w: no sample size or sample to chunk", ErrNoAudio)
+	}
+
+	sampleSize := binary.BigEndian.Uint32(stsz.Data[4:8])
+	sampleCount := int(binary.BigEndian.Uint32(stsz.Data[8:12]))
+	if sampleSize == 0 && len(stsz.Data) < 12+sampleCount*4 {
+		return nil, ErrTruncated
+	}
+
+	entryCount := int(binary.BigEndian.Uint32(stsc.Data[4:8]))
+	if len(stsc.Data) < 8+entryCount*12 {
+		return nil, ErrTruncated
+	}
+
+	chunks := make([]chunk, len(offsets))
+	sample := 0
+	for idx := range offsets {
+		perChunk := 0
+		for entry := 0; entry < entryCount; entry++ {
+			pos := 8 + entry*12
+			if int(binary.BigEndian.Uint32(stsc.Data[pos:pos+4])) > idx+1 {
+				break
+			}
+			perChunk = int(binary.BigEndian.Uint32(stsc.Data[pos+4 : pos+8]))
+		}
+
+		chunks[idx].offset = offsets[idx]
+		for i := 0; i < perChunk && sample < sampleCount; i++ {
+			if sampleSize != 0 {
+				chunks[idx].size += int64(sampleSize)
+			} else {
+				pos := 12 + sample*4
+				chunks[idx].size += int64(binary.BigEndian.Uint32(stsz.Data[pos : pos+4]))
+			}
+			sample++
+		}
+	}
+
+	return chunks, nil
+}
+
+func readChunkOffsets(stbl *Node) ([]int64, error) {
+	if stco := stbl.Child("stco"); stco != nil && len(stco.Data) >= 8 {
+		count := int(binary.BigEndian.Uint32(stco.Data[4:8]))
+		if len(stco.Data) < 8+count*4 {
+			return nil, ErrTruncated
+		}
+
+		offsets := make([]int64, count)
+		for idx := range offsets {
+			offsets[idx] = int64(binary.BigEndian.Uint32(stco.Data[8+idx*4:]))
+		}
+		return offsets, nil
+	}
+
+	if co64 := stbl.Child("co64"); co64 != nil && len(co64.Data) >= 8 {
+		count := int(binary.BigEndian.Uint32(co64.Data[4:8]))
+		if len(co64.Data) < 8+count*8 {
+			return nil, ErrTruncated
+		}
+
+		offsets := make([]int64, count)
+		for idx := range offsets {
+			offsets[idx] = int64(binary.BigEndian.Uint64(co64.Data[8+idx*8:]))
+		}
+		return offsets, nil
+	}
+
+	return nil, fmt.Errorf("%w: no chunk offset", ErrNoAudio)
+}
+
+// setChunkOffsets replace the chunk offsets with a stco of the chunks
+// written one after another from start.
+func setChunkOffsets(stbl *Node, chunks []chunk, start int64) {
+	data := make([]byte, 8+len(chunks)*4)
+	binary.BigEndian.PutUint32(data[4:8], uint32(len(chunks)))
+	offset := start
+	for idx, c := range chunks {
+		binary.BigEndian.PutUint32(data[8+idx*4:], uint32(offset))
+		offset += c.size
+	}
+
+	stco := NewNode("stco", data)
+	for idx, child := range stbl.Children {
+		if child.Type == "stco" || child.Type == "co64" {
+			stbl.Children[idx] = stco
+			return
+		}
+	}
+	stbl.Children = append(stbl.Children, stco)
+}
+
+// metaNode returns the iTunes metadata box with the tags which are set.
+func metaNode(tags Tags) *Node {
+	hdlr := NewNode("hdlr", append(make([]byte, 8), []byte("mdirappl\x00\x00\x00\x00\x00\x00\x00\x00\x00")...))
+	ilst := NewNode("ilst", nil)
+
+	text := func(boxType, value string) {
+		if value == "" {
+			return
+		}
+		ilst.Children = append(ilst.Children, NewNode(boxType, nil, dataNode(1, []byte(value))))
+	}
+
+	text("\xa9nam", tags.Title)
+	text("\xa9alb", tags.Album)
+	text("\xa9ART", tags.Artist)
+	text("aART", tags.Artist)
+	if tags.Track > 0 {
+		trkn := make([]byte, 8)
+		binary.BigEndian.PutUint16(trkn[2:4], uint16(tags.Track))
+		binary.BigEndian.PutUint16(trkn[4:6], uint16(tags.TrackTotal))
+		ilst.Children = append(ilst.Children, NewNode("trkn", nil, dataNode(0, trkn)))
+	}
+
+	return NewNode("meta", make([]byte, 4), hdlr, ilst)
+}
+
+func dataNode(kind uint32, value []byte) *Node {
+	data := make([]byte, 8, 8+len(value))
+	binary.BigEndian.PutUint32(data, kind)
+	return NewNode("data", append(data, value...))
+}
@@ -0,0 +1,288 @@
+package mp4
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// audioChunks are the samples of the audio track of the fixture, a chunk
+// is a list of samples.
+var audioChunks = [][]string{{"aa", "bbb"}, {"cccc"}, {"d", "ee"}}
+
+// fixtureMP4 returns a small mp4 with a video and an audio track, the chunks
+// of both tracks are interleaved in the mdat written before the moov.
+func fixtureMP4(t *testing.T, co64 bool) []byte {
+	t.Helper()
+
+	ftyp := NewNode("ftyp", []byte("isom\x00\x00\x02\x00isomiso2mp41"))
+
+	mdat := []byte{}
+	start := ftyp.Size() + 8
+	var videoOffsets, audioOffsets []int64
+	var sampleSizes []uint32
+	for _, samples := range audioChunks {
+		videoOffsets = append(videoOffsets, start+int64(len(mdat)))
+		mdat = append(mdat, "VVVVVV"...)
+
+		audioOffsets = append(audioOffsets, start+int64(len(mdat)))
+		for _, sample := range samples {
+			mdat = append(mdat, sample...)
+			sampleSizes = append(sampleSizes, uint32(len(sample)))
+		}
+	}
+
+	stsz := fullBox(0, uint32(len(sampleSizes)))
+	for _, size := range sampleSizes {
+		stsz = binary.BigEndian.AppendUint32(stsz, size)
+	}
+
+	// 2 samples in the first chunk, 1 in the second and 2 from the third
+	stsc := fullBox(3)
+	stsc = appendUint32(stsc, 1, 2, 1)
+	stsc = appendUint32(stsc, 2, 1, 1)
+	stsc = appendUint32(stsc, 3, 2, 1)
+
+	audioStbl := NewNode("stbl", nil,
+		NewNode("stsd", fullBox(0)),
+		NewNode("stsz", stsz),
+		NewNode("stsc", stsc),
+		chunkOffsetNode(audioOffsets, co64),
+	)
+
+	videoStbl := NewNode("stbl", nil,
+		NewNode("stsd", fullBox(0)),
+		NewNode("stsz", fullBox(6, uint32(len(videoOffsets)))),
+		NewNode("stsc", appendUint32(fullBox(1), 1, 1, 1)),
+		chunkOffsetNode(videoOffsets, co64),
+	)
+
+	moov := NewNode("moov", nil,
+		NewNode("mvhd", make([]byte, 100)),
+		trakNode("vide", videoStbl),
+		trakNode("soun", audioStbl),
+	)
+
+	buf := ftyp.Bytes()
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header, uint32(len(mdat)+8))
+	copy(header[4:], "mdat")
+	buf = append(buf, header...)
+	buf = append(buf, mdat...)
+	return append(buf, moov.Bytes()...)
+}
+
+func trakNode(handler string, stbl *Node) *Node {
+	hdlr := append(make([]byte, 8), handler...)
+	hdlr = append(hdlr, make([]byte, 13)...)
+	return NewNode("trak", nil,
+		NewNode("tkhd", make([]byte, 84)),
+		NewNode("mdia", nil,
+			NewNode("mdhd", make([]byte, 24)),
+			NewNode("hdlr", hdlr),
+			NewNode("minf", nil, stbl),
+		),
+	)
+}
+
+func chunkOffsetNode(offsets []int64, co64 bool) *Node {
+	data := fullBox(uint32(len(offsets)))
+	for _, offset := range offsets {
+		if co64 {
+			data = binary.BigEndian.AppendUint64(data, uint64(offset))
+		} else {
+			data = binary.BigEndian.AppendUint32(data, uint32(offset))
+		}
+	}
+
+	if co64 {
+		return NewNode("co64", data)
+	}
+	return NewNode("stco", data)
+}
+
+// fullBox returns the version and flags followed by the values.
+func fullBox(values ...uint32) []byte {
+	return appendUint32(make([]byte, 4), values...)
+}
+
+func appendUint32(data []byte, values ...uint32) []byte {
+	for _, value := range values {
+		data = binary.BigEndian.AppendUint32(data, value)
+	}
+	return data
+}
+
+func readStbl(t *testing.T, data []byte) *Node {
+	t.Helper()
+
+	r := bytes.NewReader(data)
+	boxes, err := ReadBoxes(r, 0, int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	moovBox, ok := Find(boxes, "moov")
+	if !ok {
+		t.Fatal("no moov box")
+	}
+
+	moov, err := ReadNode(r, moovBox)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trak := audioTrak(moov)
+	if trak == nil {
+		t.Fatal("no audio track")
+	}
+	return trak.Path("mdia", "minf", "stbl")
+}
+
+func chunkData(data []byte, chunks []chunk) []string {
+	values := make([]string, len(chunks))
+	for idx, c := range chunks {
+		values[idx] = string(data[c.offset : c.offset+c.size])
+	}
+	return values
+}
+
+func TestReadChunks(t *testing.T) {
+	want := []string{"aabbb", "cccc", "dee"}
+	for _, co64 := range []bool{false, true} {
+		data := fixtureMP4(t, co64)
+		chunks, err := readChunks(readStbl(t, data))
+		if err != nil {
+			t.Fatalf("co64 %v: %s", co64, err)
+		}
+
+		if got := chunkData(data, chunks); !reflect.DeepEqual(got, want) {
+			t.Errorf("co64 %v: chunks %q, want %q", co64, got, want)
+		}
+	}
+}
+
+func TestReadChunksSameSampleSize(t *testing.T) {
+	stbl := NewNode("stbl", nil,
+		NewNode("stsz", fullBox(3, 5)),
+		NewNode("stsc", appendUint32(fullBox(1), 1, 2, 1)),
+		chunkOffsetNode([]int64{100, 200, 300}, false),
+	)
+
+	chunks, err := readChunks(stbl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the last chunk has only the 5th sample left
+	want := []chunk{{100, 6}, {200, 6}, {300, 3}}
+	if !reflect.DeepEqual(chunks, want) {
+		t.Errorf("chunks %v, want %v", chunks, want)
+	}
+}
+
+func TestReadChunksTruncated(t *testing.T) {
+	stbl := NewNode("stbl", nil,
+		NewNode("stsz", fullBox(0, 4, 1)),
+		NewNode("stsc", appendUint32(fullBox(1), 1, 1, 1)),
+		chunkOffsetNode([]int64{100}, false),
+	)
+
+	if _, err := readChunks(stbl); !errors.Is(err, ErrTruncated) {
+		t.Errorf("error %v, want %v", err, ErrTruncated)
+	}
+}
+
+func TestSetChunkOffsets(t *testing.T) {
+	for _, co64 := range []bool{false, true} {
+		stbl := NewNode("stbl", nil,
+			NewNode("stsz", fullBox(0, 0)),
+			chunkOffsetNode([]int64{1 << 33, 1 << 34}, co64),
+		)
+
+		setChunkOffsets(stbl, []chunk{{1 << 33, 10}, {1 << 34, 20}}, 50)
+		if len(stbl.Children) != 2 || stbl.Child("co64") != nil {
+			t.Fatalf("co64 %v: stbl children %d, want the chunk offsets replaced by a stco", co64, len(stbl.Children))
+		}
+
+		offsets, err := readChunkOffsets(stbl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []int64{50, 60}; !reflect.DeepEqual(offsets, want) {
+			t.Errorf("co64 %v: offsets %v, want %v", co64, offsets, want)
+		}
+	}
+}
+
+func TestExtractAudio(t *testing.T) {
+	for _, co64 := range []bool{false, true} {
+		data := fixtureMP4(t, co64)
+
+		var out bytes.Buffer
+		tags := Tags{Title: "Lesson", Album: "Class", Artist: "Teacher", Track: 2, TrackTotal: 3}
+		if err := ExtractAudio(bytes.NewReader(data), int64(len(data)), &out, tags); err != nil {
+			t.Fatalf("co64 %v: %s", co64, err)
+		}
+
+		m4a := out.Bytes()
+		if err := Validate(bytes.NewReader(m4a), int64(len(m4a))); err != nil {
+			t.Fatalf("co64 %v: invalid m4a: %s", co64, err)
+		}
+
+		boxes, err := ReadBoxes(bytes.NewReader(m4a), 0, int64(len(m4a)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		mdat, _ := Find(boxes, "mdat")
+		if got := string(m4a[mdat.DataOffset() : mdat.Offset+mdat.Size]); got != "aabbbccccdee" {
+			t.Errorf("co64 %v: mdat %q, want only the audio samples", co64, got)
+		}
+
+		chunks, err := readChunks(readStbl(t, m4a))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := chunkData(m4a, chunks), []string{"aabbb", "cccc", "dee"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("co64 %v: chunks %q, want %q", co64, got, want)
+		}
+
+		moovBox, _ := Find(boxes, "moov")
+		moov, err := ReadNode(bytes.NewReader(m4a), moovBox)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		traks := 0
+		for _, child := range moov.Children {
+			if child.Type == "trak" {
+				traks++
+			}
+		}
+		if traks != 1 {
+			t.Errorf("co64 %v: %d tracks, want only the audio track", co64, traks)
+		}
+
+		// meta is not a container, the tags are in its payload
+		meta := moov.Path("udta", "meta")
+		if meta == nil || !bytes.Contains(meta.Data, []byte("Lesson")) {
+			t.Errorf("co64 %v: no title in the metadata", co64)
+		}
+	}
+}
+
+func TestExtractAudioNoAudio(t *testing.T) {
+	data := NewNode("ftyp", []byte("isom\x00\x00\x02\x00isom")).Bytes()
+	data = append(data, NewNode("moov", nil,
+		NewNode("mvhd", make([]byte, 100)),
+		trakNode("vide", NewNode("stbl", nil)),
+	).Bytes()...)
+
+	err := ExtractAudio(bytes.NewReader(data), int64(len(data)), &bytes.Buffer{}, Tags{})
+	if !errors.Is(err, ErrNoAudio) {
+		t.Errorf("error %v, want %v", err, ErrNoAudio)
+	}
+}
@@ -0,0 +1,136 @@
+package mp4
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+var (
+	ErrTruncated = errors.New("mp4 box is truncated")
+	ErrNoFtyp    = errors.New("mp4 has no ftyp box")
+	ErrNoMoov    = errors.New("mp4 has no moov box")
+	ErrNoMdat    = errors.New("mp4 has no mdat box")
+)
+
+// Box is the position of a box inside the file, Offset point to the start of
+// the header and Size include the header.
+type Box struct {
+	Type       string
+	Offset     int64
+	Size       int64
+	HeaderSize int64
+}
+
+// DataOffset returns the position of the box payload.
+func (b Box) DataOffset() int64 {
+	return b.Offset + b.HeaderSize
+}
+
+// DataSize returns the size of the box payload.
+func (b Box) DataSize() int64 {
+	return b.Size - b.HeaderSize
+}
+
+// ReadBoxes returns the boxes between offset and end, the children of a box
+// are read by calling it again with the payload range.
+func ReadBoxes(r io.ReaderAt, offset, end int64) ([]Box, error) {
+	var boxes []Box
+	header := make([]byte, 16)
+	for offset < end {
+		if end-offset < 8 {
+			return boxes, ErrTruncated
+		}
+
+		if _, err := r.ReadAt(header[:8], offset); err != nil {
+			return boxes, ErrTruncated
+		}
+
+		box := Box{
+			Type:       string(header[4:8]),
+			Offset:     offset,
+			Size:       int64(binary.BigEndian.Uint32(header[:4])),
+			HeaderSize: 8,
+		}
+
+		switch box.Size {
+		case 0:
+			box.Size = end - offset
+		case 1:
+			if end-offset < 16 {
+				return boxes, ErrTruncated
+			}
+			if _, err := r.ReadAt(header[8:16], offset+8); err != nil {
+				return boxes, ErrTruncated
+			}
+			box.Size = int64(binary.BigEndian.Uint64(header[8:16]))
+			box.HeaderSize = 16
+		}
+
+		if box.Size < box.HeaderSize {
+			return boxes, fmt.Errorf("mp4 box %q has invalid size %d", box.Type, box.Size)
+		}
+
+		if offset+box.Size > end {
+			return boxes, fmt.Errorf("%w: %q need %d bytes, %d left", ErrTruncated, box.Type, box.Size, end-offset)
+		}
+
+		boxes = append(boxes, box)
+		offset += box.Size
+	}
+
+	return boxes, nil
+}
+
+// Find returns the first box with the type.
+func Find(boxes []Box, boxType string) (Box, bool) {
+	for _, box := range boxes {
+		if box.Type == boxType {
+			return box, true
+		}
+	}
+	return Box{}, false
+}
+
+// Validate check the top level boxes of the file, a complete download has
+// ftyp, moov and mdat and every box fit inside the file.
+func Validate(r io.ReaderAt, size int64) error {
+	boxes, err := ReadBoxes(r, 0, size)
+	if err != nil {
+		return err
+	}
+
+	if len(boxes) == 0 || boxes[0].Type != "ftyp" {
+		return ErrNoFtyp
+	}
+
+	moov, ok := Find(boxes, "moov")
+	if !ok {
+		return ErrNoMoov
+	}
+
+	if _, ok := Find(boxes, "mdat"); !ok {
+		return ErrNoMdat
+	}
+
+	_, err = ReadBoxes(r, moov.DataOffset(), moov.Offset+moov.Size)
+	return err
+}
+
+// ValidateFile is Validate for the file in path.
+func ValidateFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	return Validate(file, info.Size())
+}
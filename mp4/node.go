@@ -0,0 +1,105 @@
+package mp4
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// containers are the boxes which only hold other boxes, the other boxes are
+// kept as raw payload.
+var containers = map[string]bool{
+	"moov": true,
+	"trak": true,
+	"mdia": true,
+	"minf": true,
+	"stbl": true,
+	"edts": true,
+	"dinf": true,
+	"udta": true,
+}
+
+// Node is a box read into memory to be changed and written again, it is
+// meant for the small boxes like moov and not for mdat.
+type Node struct {
+	Type     string
+	Data     []byte
+	Children []*Node
+}
+
+// ReadNode read the box and the children of the containers.
+func ReadNode(r io.ReaderAt, box Box) (*Node, error) {
+	node := &Node{Type: box.Type}
+	if !containers[box.Type] {
+		node.Data = make([]byte, box.DataSize())
+		if _, err := r.ReadAt(node.Data, box.DataOffset()); err != nil {
+			return nil, ErrTruncated
+		}
+		return node, nil
+	}
+
+	boxes, err := ReadBoxes(r, box.DataOffset(), box.Offset+box.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, child := range boxes {
+		childNode, err := ReadNode(r, child)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, childNode)
+	}
+
+	return node, nil
+}
+
+// Child returns the first child with the type.
+func (n *Node) Child(boxType string) *Node {
+	for _, child := range n.Children {
+		if child.Type == boxType {
+			return child
+		}
+	}
+	return nil
+}
+
+// Path returns the descendant following the types, e.g. mdia, minf, stbl.
+func (n *Node) Path(types ...string) *Node {
+	node := n
+	for _, boxType := range types {
+		if node = node.Child(boxType); node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// Size returns the size of the box with the header.
+func (n *Node) Size() int64 {
+	size := int64(8 + len(n.Data))
+	for _, child := range n.Children {
+		size += child.Size()
+	}
+	return size
+}
+
+// Bytes returns the box with the header and the children.
+func (n *Node) Bytes() []byte {
+	buf := make([]byte, 8, n.Size())
+	binary.BigEndian.PutUint32(buf, uint32(n.Size()))
+	copy(buf[4:], n.Type)
+	buf = append(buf, n.Data...)
+	for _, child := range n.Children {
+		buf = append(buf, child.Bytes()...)
+	}
+	return buf
+}
+
+// NewNode returns a box with the payload.
+func NewNode(boxType string, data []byte, children ...*Node) *Node {
+	return &Node{
+		Type:     boxType,
+		Data:     data,
+		Children: children,
+	}
+}
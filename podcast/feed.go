@@ -0,0 +1,166 @@
+package podcast
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+const namespaceItunes = "http://www.itunes.com/dtds/podcast-1.0.dtd"
+
+// Channel is a podcast, the episodes are listed in order with the episode
+// number for the serial podcasts.
+type Channel struct {
+	Title       string
+	Link        string
+	Description string
+	Language    string
+	Author      string
+	Image       string
+	Category    string
+	IsSerial    bool
+	Episodes    []Episode
+}
+
+type Episode struct {
+	GUID        string
+	Title       string
+	Description string
+	URL         string
+	Type        string
+	Length      int64
+	Duration    time.Duration
+	Published   time.Time
+	Season      int
+	Number      int
+	Image       string
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Itunes  string     `xml:"xmlns:itunes,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link,omitempty"`
+	Description string        `xml:"description"`
+	Language    string        `xml:"language,omitempty"`
+	Generator   string        `xml:"generator"`
+	Author      string        `xml:"itunes:author,omitempty"`
+	Summary     string        `xml:"itunes:summary,omitempty"`
+	Type        string        `xml:"itunes:type"`
+	Explicit    string        `xml:"itunes:explicit"`
+	Image       *itunesImage  `xml:"itunes:image,omitempty"`
+	Categories  []rssCategory `xml:"itunes:category"`
+	Items       []rssItem     `xml:"item"`
+}
+
+type itunesImage struct {
+	Href string `xml:"href,attr"`
+}
+
+type rssCategory struct {
+	Text string `xml:"text,attr"`
+}
+
+type rssItem struct {
+	Title       string       `xml:"title"`
+	Description string       `xml:"description"`
+	GUID        rssGUID      `xml:"guid"`
+	PubDate     string       `xml:"pubDate,omitempty"`
+	Enclosure   rssEnclosure `xml:"enclosure"`
+	Duration    string       `xml:"itunes:duration,omitempty"`
+	Season      int          `xml:"itunes:season,omitempty"`
+	Episode     int          `xml:"itunes:episode,omitempty"`
+	EpisodeType string       `xml:"itunes:episodeType"`
+	Image       *itunesImage `xml:"itunes:image,omitempty"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+// Write the channel as a RSS 2.0 feed with the iTunes tags.
+func Write(w io.Writer, channel Channel, generator string) error {
+	feed := rss{
+		Version: "2.0",
+		Itunes:  namespaceItunes,
+		Channel: rssChannel{
+			Title:       channel.Title,
+			Link:        channel.Link,
+			Description: channel.Description,
+			Language:    channel.Language,
+			Generator:   generator,
+			Author:      channel.Author,
+			Summary:     channel.Description,
+			Type:        "episodic",
+			Explicit:    "false",
+		},
+	}
+
+	if channel.IsSerial {
+		feed.Channel.Type = "serial"
+	}
+	if channel.Image != "" {
+		feed.Channel.Image = &itunesImage{Href: channel.Image}
+	}
+	if channel.Category != "" {
+		feed.Channel.Categories = []rssCategory{{Text: channel.Category}}
+	}
+
+	for _, episode := range channel.Episodes {
+		item := rssItem{
+			Title:       episode.Title,
+			Description: episode.Description,
+			GUID:        rssGUID{Value: episode.GUID},
+			Enclosure: rssEnclosure{
+				URL:    episode.URL,
+				Length: episode.Length,
+				Type:   episode.Type,
+			},
+			Season:      episode.Season,
+			Episode:     episode.Number,
+			EpisodeType: "full",
+		}
+
+		if !episode.Published.IsZero() {
+			item.PubDate = episode.Published.Format(time.RFC1123Z)
+		}
+		if episode.Duration > 0 {
+			item.Duration = formatDuration(episode.Duration)
+		}
+		if episode.Image != "" {
+			item.Image = &itunesImage{Href: episode.Image}
+		}
+		feed.Channel.Items = append(feed.Channel.Items, item)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(feed); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+}
@@ -0,0 +1,63 @@
+package podcast
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+)
+
+// mediaTypes are the files served by the handler, the other files of the
+// download root like the cookies and the indexes are not shared.
+var mediaTypes = map[string]string{
+	".xml": "application/rss+xml",
+	".mp4": "video/mp4",
+	".m4a": "audio/mp4",
+	".vtt": "text/vtt",
+	".jpg": "image/jpeg",
+	".png": "image/png",
+}
+
+// MediaType returns the mime type of the file, empty when it is not served.
+func MediaType(filePath string) string {
+	ext := strings.ToLower(path.Ext(filePath))
+	if ext == ".xml" && path.Base(filePath) != constants.FilenameFeed {
+		return ""
+	}
+	return mediaTypes[ext]
+}
+
+// Handler serve the feeds and the media of the download root, the range
+// requests are supported for the players.
+func Handler(root string) http.Handler {
+	files := http.FileServer(http.Dir(root))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		name := path.Clean("/" + r.URL.Path)
+		mediaType := MediaType(name)
+		if mediaType == "" || strings.HasSuffix(r.URL.Path, "/") {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", mediaType)
+		files.ServeHTTP(w, r)
+	})
+}
+
+// URL returns the url of the file served from the base url, the path is
+// relative to the served root.
+func URL(baseURL, relPath string) string {
+	parts := strings.Split(filepath.ToSlash(relPath), "/")
+	for idx := range parts {
+		parts[idx] = url.PathEscape(parts[idx])
+	}
+	return strings.TrimRight(baseURL, "/") + "/" + strings.Join(parts, "/")
+}
@@ -0,0 +1,195 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/utils"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	ErrClassNotFound = errors.New("class not found in queue")
+
+	bucketClasses = []byte("classes")
+)
+
+// Queue is the download state of the classes in the download root. The
+// database is opened for every operation, so the cli and a running server
+// can use the same queue.
+type Queue struct {
+	path string
+}
+
+func New(root string) *Queue {
+	return &Queue{
+		path: filepath.Join(root, constants.FilenameQueue),
+	}
+}
+
+func (q *Queue) Path() string {
+	return q.path
+}
+
+func (q *Queue) open() (*bolt.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(q.path), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(q.path, 0o644, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open queue %s: %w", q.path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucketClasses)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func (q *Queue) update(fn func(bucket *bolt.Bucket) error) error {
+	db, err := q.open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.Update(func(tx *bolt.Tx) error {
+		return fn(tx.Bucket(bucketClasses))
+	})
+}
+
+func (q *Queue) view(fn func(bucket *bolt.Bucket) error) error {
+	db, err := q.open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.View(func(tx *bolt.Tx) error {
+		return fn(tx.Bucket(bucketClasses))
+	})
+}
+
+func (q *Queue) Get(id int) (*models.QueueClass, error) {
+	dest := &models.QueueClass{}
+	err := q.view(func(bucket *bolt.Bucket) error {
+		value := bucket.Get(classKey(id))
+		if value == nil {
+			return fmt.Errorf("%w: %d", ErrClassNotFound, id)
+		}
+		return json.Unmarshal(value, dest)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+// Update read, change and write the class in one transaction, the class is
+// created when missing.
+func (q *Queue) Update(id int, fn func(class *models.QueueClass) error) (*models.QueueClass, error) {
+	class := &models.QueueClass{}
+	err := q.update(func(bucket *bolt.Bucket) error {
+		if value := bucket.Get(classKey(id)); value != nil {
+			if err := json.Unmarshal(value, class); err != nil {
+				return err
+			}
+		} else {
+			class.ClassID = id
+			class.Status = models.JobQueued
+			class.CreatedAt = time.Now()
+		}
+
+		if err := fn(class); err != nil {
+			return err
+		}
+
+		class.UpdatedAt = time.Now()
+		value, err := json.Marshal(class)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(classKey(id), value)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return class, nil
+}
+
+// List returns the classes sorted by the created time.
+func (q *Queue) List() ([]models.QueueClass, error) {
+	var classes []models.QueueClass
+	err := q.view(func(bucket *bolt.Bucket) error {
+		return bucket.ForEach(func(_, value []byte) error {
+			var class models.QueueClass
+			if err := json.Unmarshal(value, &class); err != nil {
+				return err
+			}
+			classes = append(classes, class)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(classes, func(i, j int) bool {
+		return classes[i].CreatedAt.Before(classes[j].CreatedAt)
+	})
+	return classes, nil
+}
+
+// Clear remove the classes with one of the status, every class is removed
+// when no status is given, and returns the number of removed classes.
+func (q *Queue) Clear(status ...string) (int, error) {
+	count := 0
+	err := q.update(func(bucket *bolt.Bucket) error {
+		var keys [][]byte
+		err := bucket.ForEach(func(key, value []byte) error {
+			var class models.QueueClass
+			if err := json.Unmarshal(value, &class); err != nil {
+				return err
+			}
+
+			if len(status) == 0 || utils.Contains(status, class.Status) {
+				keys = append(keys, append([]byte(nil), key...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+		count = len(keys)
+		return nil
+	})
+
+	return count, err
+}
+
+func classKey(id int) []byte {
+	return []byte(strconv.Itoa(id))
+}
@@ -0,0 +1,27 @@
+package reporter
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+)
+
+type jsonReporter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSON returns a reporter which writes every event as a line of json.
+func NewJSON(w io.Writer) Reporter {
+	return &jsonReporter{
+		enc: json.NewEncoder(w),
+	}
+}
+
+func (j *jsonReporter) Report(event models.Event) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	_ = j.enc.Encode(event)
+}
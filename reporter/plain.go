@@ -0,0 +1,117 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/utils"
+)
+
+type plain struct {
+	mu       sync.Mutex
+	w        io.Writer
+	index    int
+	percent  int64
+	lastLine time.Time
+}
+
+// NewPlain returns a reporter for the output which is not a terminal like a
+// file or a CI log, the progress is written as a line every 10% or 5 seconds.
+func NewPlain(w io.Writer) Reporter {
+	return &plain{w: w}
+}
+
+func (p *plain) Report(event models.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch event.Type {
+	case models.EventStart:
+		p.percent = 0
+		p.lastLine = time.Now()
+		switch event.Stage {
+		case models.StageClass:
+			p.println("Fetching skillshare class data with id %d", event.ClassID)
+		case models.StageMetadata:
+			p.println("Fetching skillshare video data of %d lessons", event.Total)
+		case models.StageSubtitle:
+			p.println("Downloading skillshare subtitle data")
+		}
+	case models.EventStep:
+		if event.Stage == models.StageMetadata && p.isDue(int64(event.Current), int64(event.Total)) {
+			p.println("[%d/%d] Fetching skillshare video data", event.Current, event.Total)
+		}
+	case models.EventProgress:
+		p.progress(event.Progress)
+	case models.EventDone:
+		switch event.Stage {
+		case models.StageClass:
+			p.println("Skillshare class data is ready: %s", event.Message)
+		case models.StageMetadata:
+			p.println("Fetching skillshare video done")
+		case models.StageSubtitle:
+			p.println("Download skillshare subtitle done, %d files", event.Current)
+		}
+	case models.EventError:
+		if event.Lesson != "" {
+			p.println("Failed %s %s: %s", event.Stage, event.Lesson, event.Error)
+			return
+		}
+		p.println("Failed %s: %s", event.Stage, event.Error)
+	}
+}
+
+func (p *plain) progress(progress *models.Progress) {
+	if progress == nil {
+		return
+	}
+
+	if progress.Index != p.index {
+		p.index = progress.Index
+		p.percent = 0
+		p.lastLine = time.Now()
+	}
+
+	counter := fmt.Sprintf("[%d/%d] %s", progress.Index, progress.Total, progress.Lesson)
+	if progress.Done {
+		p.println("%s done, %s", counter, utils.FormatBytes(progress.TotalBytes))
+		return
+	}
+
+	if progress.TotalBytes <= 0 || !p.isDue(progress.Bytes, progress.TotalBytes) {
+		return
+	}
+
+	p.println("%s %d%% %s/%s, %s/s",
+		counter,
+		progress.Bytes*100/progress.TotalBytes,
+		utils.FormatBytes(progress.Bytes),
+		utils.FormatBytes(progress.TotalBytes),
+		utils.FormatBytes(progress.Speed),
+	)
+}
+
+// isDue returns true when the progress pass the next step of percent or the
+// last line is older than the interval.
+func (p *plain) isDue(current, total int64) bool {
+	if total <= 0 {
+		return false
+	}
+
+	percent := current * 100 / total
+	if percent < p.percent+constants.PlainProgressPercent && time.Since(p.lastLine) < constants.PlainProgressInterval {
+		return false
+	}
+
+	p.percent = percent - percent%constants.PlainProgressPercent
+	p.lastLine = time.Now()
+	return true
+}
+
+func (p *plain) println(format string, args ...interface{}) {
+	fmt.Fprintf(p.w, format+"\n", args...)
+}
@@ -0,0 +1,38 @@
+// Package reporter observes the events of the download pipeline, so the
+// progress is shown by the caller instead of the core services.
+package reporter
+
+import "github.com/rizalarfiyan/skillshare-downloader/models"
+
+type Reporter interface {
+	Report(event models.Event)
+}
+
+// Func is an adapter to use an ordinary function as a Reporter.
+type Func func(event models.Event)
+
+func (fn Func) Report(event models.Event) {
+	fn(event)
+}
+
+type quiet struct{}
+
+// NewQuiet returns a reporter which ignores every event.
+func NewQuiet() Reporter {
+	return quiet{}
+}
+
+func (quiet) Report(models.Event) {}
+
+type multi []Reporter
+
+// NewMulti returns a reporter which sends every event to all reporters.
+func NewMulti(reporters ...Reporter) Reporter {
+	return multi(reporters)
+}
+
+func (m multi) Report(event models.Event) {
+	for _, r := range m {
+		r.Report(event)
+	}
+}
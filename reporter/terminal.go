@@ -0,0 +1,113 @@
+package reporter
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/cheggaaa/pb/v3"
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+)
+
+type terminal struct {
+	mu    sync.Mutex
+	color bool
+	spin  *spinner.Spinner
+	bar   *pb.ProgressBar
+}
+
+// NewTerminal returns a reporter with a spinner for the fetch stages and a
+// progress bar for every lesson download, both are written to the stderr.
+func NewTerminal(color bool) Reporter {
+	// the spinner write to the stderr like the progress bars
+	spin := spinner.New(spinner.CharSets[78], 100*time.Millisecond, spinner.WithWriterFile(os.Stderr))
+	if color {
+		_ = spin.Color("green")
+	}
+
+	return &terminal{
+		color: color,
+		spin:  spin,
+	}
+}
+
+func (t *terminal) Report(event models.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch event.Type {
+	case models.EventStart:
+		if event.Stage == models.StageVideo {
+			return
+		}
+		t.spin.Suffix = t.suffix(event)
+		t.spin.Start()
+	case models.EventStep:
+		if event.Stage == models.StageVideo {
+			return
+		}
+		t.spin.Suffix = t.suffix(event)
+	case models.EventProgress:
+		t.progress(event.Progress)
+	case models.EventDone:
+		if event.Stage == models.StageVideo {
+			return
+		}
+		t.spin.Suffix = t.suffix(event)
+		t.spin.Stop()
+	}
+}
+
+func (t *terminal) suffix(event models.Event) string {
+	switch event.Stage {
+	case models.StageClass:
+		if event.Type == models.EventDone {
+			return " Fetching skillshare done\n"
+		}
+		return fmt.Sprintf(" Fetching skillshare class data with id %d\n", event.ClassID)
+	case models.StageMetadata:
+		if event.Type == models.EventDone {
+			return " Fetching skillshare video done\n"
+		}
+		return fmt.Sprintf(" %s Fetching skillshare video data with id\n", t.counter(event))
+	case models.StageSubtitle:
+		if event.Type == models.EventDone {
+			return " Download skillshare subtitle done\n"
+		}
+		return fmt.Sprintf(" %s Download skillshare subtitle data %s\n", t.counter(event), event.Message)
+	}
+	return ""
+}
+
+func (t *terminal) counter(event models.Event) string {
+	counter := fmt.Sprintf("[%d/%d]", event.Current, event.Total)
+	if t.color {
+		counter = fmt.Sprintf("\x1b[36m%s\x1b[0m", counter)
+	}
+	return counter
+}
+
+func (t *terminal) progress(progress *models.Progress) {
+	if progress == nil {
+		return
+	}
+
+	if t.bar == nil && progress.TotalBytes > 0 {
+		t.bar = pb.ProgressBarTemplate(constants.ProgressBarTemplate).Start64(progress.TotalBytes)
+		t.bar.Set(pb.Bytes, true)
+	}
+
+	if t.bar == nil {
+		return
+	}
+
+	t.bar.SetCurrent(progress.Bytes)
+	if progress.Done {
+		t.bar.SetCurrent(t.bar.Total())
+		t.bar.Finish()
+		t.bar = nil
+	}
+}
@@ -0,0 +1,382 @@
+package search
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/subtitle"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	ErrEmptyPhrase = errors.New("search phrase is empty")
+
+	bucketFiles = []byte("files")
+	bucketDocs  = []byte("docs")
+	bucketTerms = []byte("terms")
+)
+
+// postingSize is a posting of a term, the doc id and the cue index.
+const postingSize = 12
+
+// Index is an inverted index of the subtitles, every term point to the cues
+// where it is said.
+type Index struct {
+	db *bolt.DB
+}
+
+type fileEntry struct {
+	ID      uint64    `json:"id"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// Open the search index in the download root, it is created when missing.
+func Open(root string) (*Index, error) {
+	pathfile := filepath.Join(root, constants.FilenameSearch)
+	db, err := bolt.Open(pathfile, 0o644, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open search index %s: %w", pathfile, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{bucketFiles, bucketDocs, bucketTerms} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Index{db: db}, nil
+}
+
+func (i *Index) Close() error {
+	return i.db.Close()
+}
+
+// IsFresh returns true when the file is indexed with the same size and
+// modification time.
+func (i *Index) IsFresh(path string, size int64, modTime time.Time) bool {
+	fresh := false
+	_ = i.db.View(func(tx *bolt.Tx) error {
+		entry, ok := getFile(tx, path)
+		fresh = ok && entry.Size == size && entry.ModTime.Equal(modTime)
+		return nil
+	})
+	return fresh
+}
+
+// Paths returns the path of every indexed file.
+func (i *Index) Paths() ([]string, error) {
+	var paths []string
+	err := i.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketFiles).ForEach(func(key, _ []byte) error {
+			paths = append(paths, string(key))
+			return nil
+		})
+	})
+	return paths, err
+}
+
+// Put index the doc, the previous index of the same path is replaced.
+func (i *Index) Put(doc models.SearchDoc) error {
+	return i.db.Update(func(tx *bolt.Tx) error {
+		if err := deleteDoc(tx, doc.Path); err != nil {
+			return err
+		}
+
+		docs := tx.Bucket(bucketDocs)
+		id, err := docs.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		value, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+
+		if err := docs.Put(docKey(id), value); err != nil {
+			return err
+		}
+
+		entry, err := json.Marshal(fileEntry{ID: id, Size: doc.Size, ModTime: doc.ModTime})
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Bucket(bucketFiles).Put([]byte(doc.Path), entry); err != nil {
+			return err
+		}
+
+		terms := tx.Bucket(bucketTerms)
+		for term, cues := range docTerms(doc) {
+			postings := append([]byte{}, terms.Get([]byte(term))...)
+			for _, cue := range cues {
+				postings = append(postings, posting(id, cue)...)
+			}
+			if err := terms.Put([]byte(term), postings); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Delete remove the file from the index.
+func (i *Index) Delete(path string) error {
+	return i.db.Update(func(tx *bolt.Tx) error {
+		return deleteDoc(tx, path)
+	})
+}
+
+// Find returns the cues where the words of the phrase are said in order,
+// the phrase can continue in the next cues.
+func (i *Index) Find(phrase string, limit int) ([]models.SearchResult, error) {
+	words := Tokens(phrase)
+	if len(words) == 0 {
+		return nil, ErrEmptyPhrase
+	}
+
+	results := []models.SearchResult{}
+	err := i.db.View(func(tx *bolt.Tx) error {
+		candidates := findCandidates(tx, words)
+		for _, id := range sortedKeys(candidates) {
+			value := tx.Bucket(bucketDocs).Get(docKey(id))
+			if value == nil {
+				continue
+			}
+
+			var doc models.SearchDoc
+			if err := json.Unmarshal(value, &doc); err != nil {
+				return err
+			}
+
+			results = append(results, matchDoc(doc, candidates[id], words)...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(results, func(a, b int) bool {
+		if results[a].ClassTitle != results[b].ClassTitle {
+			return results[a].ClassTitle < results[b].ClassTitle
+		}
+		if results[a].Index != results[b].Index {
+			return results[a].Index < results[b].Index
+		}
+		return results[a].Start < results[b].Start
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// Tokens returns the lower case words of the text, the punctuation is
+// removed.
+func Tokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r)
+	})
+}
+
+// findCandidates returns the docs having every word with the cues having
+// the first word.
+func findCandidates(tx *bolt.Tx, words []string) map[uint64][]int {
+	terms := tx.Bucket(bucketTerms)
+	var candidates map[uint64][]int
+	for idx := len(words) - 1; idx >= 0; idx-- {
+		found := make(map[uint64][]int)
+		postings := terms.Get([]byte(words[idx]))
+		for pos := 0; pos+postingSize <= len(postings); pos += postingSize {
+			id := binary.BigEndian.Uint64(postings[pos:])
+			if candidates != nil && candidates[id] == nil {
+				continue
+			}
+			found[id] = append(found[id], int(binary.BigEndian.Uint32(postings[pos+8:])))
+		}
+		candidates = found
+	}
+	return candidates
+}
+
+func matchDoc(doc models.SearchDoc, cues []int, words []string) []models.SearchResult {
+	phrase := " " + strings.Join(words, " ") + " "
+	var results []models.SearchResult
+	last := -constants.SearchWindowCues
+	sort.Ints(cues)
+	for _, cue := range cues {
+		if cue >= len(doc.Cues) || cue-last < constants.SearchWindowCues {
+			continue
+		}
+
+		first := " " + strings.Join(Tokens(doc.Cues[cue].Text), " ") + " "
+		end := cue + constants.SearchWindowCues
+		if end > len(doc.Cues) {
+			end = len(doc.Cues)
+		}
+
+		var window []string
+		for _, next := range doc.Cues[cue:end] {
+			window = append(window, Tokens(next.Text)...)
+		}
+
+		// the match must start in this cue, the next cues only finish it
+		pos := strings.Index(" "+strings.Join(window, " ")+" ", phrase)
+		if pos < 0 || pos >= len(first)-1 {
+			continue
+		}
+
+		last = cue
+		results = append(results, models.SearchResult{
+			ClassID:    doc.ClassID,
+			ClassTitle: doc.ClassTitle,
+			Index:      doc.Index,
+			Lesson:     doc.Lesson,
+			Lang:       doc.Lang,
+			Path:       doc.Path,
+			Video:      doc.Video,
+			Start:      doc.Cues[cue].Start,
+			Timestamp:  subtitle.FormatTimestamp(doc.Cues[cue].Start),
+			Context:    cueContext(doc.Cues, cue),
+		})
+	}
+	return results
+}
+
+// cueContext returns the text around the cue without the repeated words of
+// the rolling captions.
+func cueContext(cues []models.SearchCue, cue int) string {
+	start, end := cue-1, cue+2
+	if start < 0 {
+		start = 0
+	}
+	if end > len(cues) {
+		end = len(cues)
+	}
+
+	var parts []subtitle.Cue
+	for _, item := range cues[start:end] {
+		// the pause is ignored to keep the context in one paragraph
+		parts = append(parts, subtitle.Cue{Start: item.Start, End: item.Start, Text: item.Text})
+	}
+
+	var texts []string
+	for _, paragraph := range subtitle.Paragraphs(parts) {
+		texts = append(texts, paragraph.Text)
+	}
+
+	text := strings.Join(texts, " ")
+	if len(text) > constants.SearchContextBytes {
+		text = strings.ToValidUTF8(text[:constants.SearchContextBytes], "") + "..."
+	}
+	return text
+}
+
+// docTerms returns the cues of every term of the doc.
+func docTerms(doc models.SearchDoc) map[string][]int {
+	terms := make(map[string][]int)
+	for idx, cue := range doc.Cues {
+		for _, term := range Tokens(cue.Text) {
+			cues := terms[term]
+			if len(cues) == 0 || cues[len(cues)-1] != idx {
+				terms[term] = append(cues, idx)
+			}
+		}
+	}
+	return terms
+}
+
+func deleteDoc(tx *bolt.Tx, path string) error {
+	entry, ok := getFile(tx, path)
+	if !ok {
+		return nil
+	}
+
+	docs := tx.Bucket(bucketDocs)
+	value := docs.Get(docKey(entry.ID))
+	if value != nil {
+		var doc models.SearchDoc
+		if err := json.Unmarshal(value, &doc); err != nil {
+			return err
+		}
+
+		terms := tx.Bucket(bucketTerms)
+		prefix := docKey(entry.ID)
+		for term := range docTerms(doc) {
+			postings := terms.Get([]byte(term))
+			kept := make([]byte, 0, len(postings))
+			for pos := 0; pos+postingSize <= len(postings); pos += postingSize {
+				if !bytes.Equal(postings[pos:pos+8], prefix) {
+					kept = append(kept, postings[pos:pos+postingSize]...)
+				}
+			}
+
+			var err error
+			if len(kept) == 0 {
+				err = terms.Delete([]byte(term))
+			} else {
+				err = terms.Put([]byte(term), kept)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := docs.Delete(prefix); err != nil {
+			return err
+		}
+	}
+
+	return tx.Bucket(bucketFiles).Delete([]byte(path))
+}
+
+func getFile(tx *bolt.Tx, path string) (fileEntry, bool) {
+	var entry fileEntry
+	value := tx.Bucket(bucketFiles).Get([]byte(path))
+	if value == nil || json.Unmarshal(value, &entry) != nil {
+		return entry, false
+	}
+	return entry, true
+}
+
+func docKey(id uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, id)
+	return key
+}
+
+func posting(id uint64, cue int) []byte {
+	value := make([]byte, postingSize)
+	binary.BigEndian.PutUint64(value, id)
+	binary.BigEndian.PutUint32(value[8:], uint32(cue))
+	return value
+}
+
+func sortedKeys(candidates map[uint64][]int) []uint64 {
+	keys := make([]uint64, 0, len(candidates))
+	for id := range candidates {
+		keys = append(keys, id)
+	}
+	sort.Slice(keys, func(a, b int) bool { return keys[a] < keys[b] })
+	return keys
+}
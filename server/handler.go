@@ -0,0 +1,270 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+)
+
+var errUnsupportedMedia = errors.New("content type must be application/json")
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type enqueueRequest struct {
+	Class string `json:"class"`
+}
+
+// Handler returns the api:
+//
+//	POST   /jobs          enqueue {"class": "<url or id>"}
+//	GET    /jobs          list the jobs
+//	GET    /jobs/{id}     get the job
+//	DELETE /jobs/{id}     cancel the job
+//	GET    /events        stream the job changes as server-sent events
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs", s.handleJobs)
+	mux.HandleFunc("/jobs/", s.handleJob)
+	mux.HandleFunc("/events", s.handleEvents)
+	return s.middleware(mux)
+}
+
+// middleware reject the requests of the other websites, a browser send them
+// with the cookies of the user even without the cors headers. The Host is
+// checked against the dns rebinding and the Origin against the other pages.
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.isAllowedHost(r.Host) {
+			writeError(w, http.StatusForbidden, fmt.Errorf("host %s not allowed", r.Host))
+			return
+		}
+
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if s.opts.AllowOrigin != "*" && origin != s.opts.AllowOrigin {
+				writeError(w, http.StatusForbidden, fmt.Errorf("origin %s not allowed", origin))
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			w.Header().Set("Vary", "Origin")
+		}
+
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if !s.isAuthorized(r) {
+			writeError(w, http.StatusUnauthorized, errors.New("invalid token"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isAllowedHost accept the loopback hosts and the host of the listen
+// address, any ip is accepted when the api listen on every interface
+// because the dns rebinding needs a domain name.
+func (s *Server) isAllowedHost(hostport string) bool {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = hostport
+	}
+	host = strings.Trim(strings.ToLower(host), "[]")
+
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	if ip != nil && ip.IsLoopback() {
+		return true
+	}
+
+	listenHost, _, err := net.SplitHostPort(s.opts.Listen)
+	if err != nil {
+		return false
+	}
+	listenHost = strings.Trim(strings.ToLower(listenHost), "[]")
+
+	if listenIP := net.ParseIP(listenHost); listenHost == "" || (listenIP != nil && listenIP.IsUnspecified()) {
+		return ip != nil
+	}
+	return host == listenHost
+}
+
+// isAuthorized accept the token query for EventSource which can not set
+// the header.
+func (s *Server) isAuthorized(r *http.Request) bool {
+	if s.opts.Token == "" {
+		return true
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		token = r.URL.Query().Get("token")
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.Token)) == 1
+}
+
+func (s *Server) handleJobs(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJson(w, http.StatusOK, s.Jobs())
+	case http.MethodPost:
+		class, err := readClass(r)
+		if errors.Is(err, errUnsupportedMedia) {
+			writeError(w, http.StatusUnsupportedMediaType, err)
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		job, created, err := s.Enqueue(class)
+		if errors.Is(err, ErrJobStopping) {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		status := http.StatusOK
+		if created {
+			status = http.StatusCreated
+		}
+		writeJson(w, status, job)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	}
+}
+
+func (s *Server) handleJob(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/jobs/")
+	var (
+		job models.Job
+		err error
+	)
+
+	switch r.Method {
+	case http.MethodGet:
+		job, err = s.Job(id)
+	case http.MethodDelete:
+		job, err = s.Cancel(id)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	switch {
+	case errors.Is(err, ErrJobNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, ErrJobFinished):
+		writeError(w, http.StatusConflict, err)
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err)
+	default:
+		writeJson(w, http.StatusOK, job)
+	}
+}
+
+// handleEvents send the current jobs first, then every change until the
+// client disconnect, the job query only stream one job.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming is not supported"))
+		return
+	}
+
+	events, unsubscribe := s.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	filter := r.URL.Query().Get("job")
+	send := func(job models.Job) bool {
+		if filter != "" && job.ID != filter {
+			return true
+		}
+
+		data, err := json.Marshal(job)
+		if err != nil {
+			return false
+		}
+
+		if _, err := fmt.Fprintf(w, "event: job\ndata: %s\n\n", data); err != nil {
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+
+	for _, job := range s.Jobs() {
+		if !send(job) {
+			return
+		}
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.ctx.Done():
+			return
+		case job := <-events:
+			if !send(job) {
+				return
+			}
+		}
+	}
+}
+
+// readClass only accept a json body, the browser send a preflight request
+// for it so a page of another origin can not queue a download.
+func readClass(r *http.Request) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return "", errUnsupportedMedia
+	}
+
+	req := enqueueRequest{}
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<16)).Decode(&req); err != nil {
+		return "", fmt.Errorf("invalid json body: %w", err)
+	}
+
+	if strings.TrimSpace(req.Class) == "" {
+		return "", errors.New("class is required")
+	}
+	return strings.TrimSpace(req.Class), nil
+}
+
+func writeJson(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJson(w, status, errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,360 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rizalarfiyan/skillshare-downloader/logger"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/queue"
+	"github.com/rizalarfiyan/skillshare-downloader/reporter"
+	"github.com/rizalarfiyan/skillshare-downloader/services"
+)
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobFinished = errors.New("job is already finished")
+	ErrJobStopping = errors.New("cancelled job of the class is still stopping, try again later")
+)
+
+type Options struct {
+	// Jobs is the number of classes downloaded at the same time.
+	Jobs int
+	// Token is required as bearer token or token query when it is not empty.
+	Token string
+	// AllowOrigin is the origin allowed to call the api from a browser.
+	AllowOrigin string
+	// Listen is the address of the api, the Host header must be a loopback
+	// host or this address.
+	Listen string
+}
+
+// Server run the queued jobs with the download pipeline and publish the
+// changes of every job to the subscribers. The id of a job is the class id
+// and the jobs are kept in the download queue of the download root.
+type Server struct {
+	ctx   context.Context
+	conf  models.Config
+	opts  Options
+	queue *queue.Queue
+
+	mu          sync.Mutex
+	jobs        map[string]*models.Job
+	cancels     map[string]context.CancelCauseFunc
+	pending     chan string
+	subscribers map[chan models.Job]bool
+}
+
+func New(ctx context.Context, conf models.Config, opts Options) *Server {
+	if opts.Jobs <= 0 {
+		opts.Jobs = 1
+	}
+
+	return &Server{
+		ctx:         ctx,
+		conf:        conf,
+		opts:        opts,
+		queue:       queue.New(conf.Dir),
+		jobs:        make(map[string]*models.Job),
+		cancels:     make(map[string]context.CancelCauseFunc),
+		pending:     make(chan string, 1024),
+		subscribers: make(map[chan models.Job]bool),
+	}
+}
+
+// Start the workers, they stop when the context of the server is done. The
+// unfinished classes of the download queue are queued again.
+func (s *Server) Start() error {
+	classes, err := s.queue.List()
+	if err != nil {
+		return err
+	}
+
+	s.startWorkers()
+
+	var resumed []string
+	for _, class := range classes {
+		job := &models.Job{
+			ID:         strconv.Itoa(class.ClassID),
+			Class:      class.Class,
+			ClassID:    class.ClassID,
+			Status:     class.Status,
+			Error:      class.LastError,
+			CreatedAt:  class.CreatedAt,
+			FinishedAt: class.UpdatedAt,
+		}
+		if !job.IsFinished() {
+			logger.Infof("[%d] Resume job from download queue", class.ClassID)
+			job.Status = models.JobQueued
+			job.FinishedAt = time.Time{}
+			resumed = append(resumed, job.ID)
+		}
+
+		s.mu.Lock()
+		s.jobs[job.ID] = job
+		s.mu.Unlock()
+	}
+
+	s.schedule(resumed...)
+	return nil
+}
+
+func (s *Server) startWorkers() {
+	for worker := 0; worker < s.opts.Jobs; worker++ {
+		go func() {
+			for {
+				select {
+				case <-s.ctx.Done():
+					return
+				case id := <-s.pending:
+					s.run(id)
+				}
+			}
+		}()
+	}
+}
+
+// schedule send the jobs to the workers without blocking the caller when the
+// pending jobs are more than the buffer.
+func (s *Server) schedule(ids ...string) {
+	if len(ids) == 0 {
+		return
+	}
+
+	go func() {
+		for _, id := range ids {
+			select {
+			case <-s.ctx.Done():
+				return
+			case s.pending <- id:
+			}
+		}
+	}()
+}
+
+// Enqueue returns the unfinished job of the same class instead of adding a
+// new one, a class is not queued again until its cancelled run returned.
+func (s *Server) Enqueue(class string) (models.Job, bool, error) {
+	classID, err := models.ParseClassID(class)
+	if err != nil {
+		return models.Job{}, false, err
+	}
+
+	s.mu.Lock()
+	for _, job := range s.jobs {
+		if job.ClassID == classID && !job.IsFinished() {
+			s.mu.Unlock()
+			return *job, false, nil
+		}
+	}
+
+	// the id is the class id, the run of the cancelled job must return
+	// before the new job replace it
+	if _, isRunning := s.cancels[strconv.Itoa(classID)]; isRunning {
+		s.mu.Unlock()
+		return models.Job{}, false, ErrJobStopping
+	}
+
+	job := &models.Job{
+		ID:        strconv.Itoa(classID),
+		Class:     class,
+		ClassID:   classID,
+		Status:    models.JobQueued,
+		CreatedAt: time.Now(),
+	}
+	s.jobs[job.ID] = job
+	s.mu.Unlock()
+
+	s.persist(classID, func(class *models.QueueClass) {
+		class.Class = job.Class
+		class.Status = models.JobQueued
+		class.LastError = ""
+	})
+
+	logger.Infof("[%d] Job %s is queued", classID, job.ID)
+	s.publish(*job)
+	s.schedule(job.ID)
+	return *job, true, nil
+}
+
+func (s *Server) Jobs() []models.Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([]models.Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, *job)
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+	return jobs
+}
+
+func (s *Server) Job(id string) (models.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return models.Job{}, ErrJobNotFound
+	}
+	return *job, nil
+}
+
+// Cancel stop the running download, a queued job is skipped by the workers.
+func (s *Server) Cancel(id string) (models.Job, error) {
+	s.mu.Lock()
+	job, ok := s.jobs[id]
+	if !ok {
+		s.mu.Unlock()
+		return models.Job{}, ErrJobNotFound
+	}
+
+	if job.IsFinished() {
+		s.mu.Unlock()
+		return *job, ErrJobFinished
+	}
+
+	cancel, isRunning := s.cancels[id]
+	if isRunning {
+		cancel(services.ErrCancelled)
+	}
+
+	job.Status = models.JobCancelled
+	job.FinishedAt = time.Now()
+	snapshot := *job
+	s.mu.Unlock()
+
+	// the pipeline write the cancelled state of a running job
+	if !isRunning {
+		s.persist(snapshot.ClassID, func(class *models.QueueClass) {
+			class.Status = models.JobCancelled
+		})
+	}
+
+	logger.Infof("[%d] Job %s is cancelled", snapshot.ClassID, id)
+	s.publish(snapshot)
+	return snapshot, nil
+}
+
+// Subscribe returns a channel receiving every change of the jobs until
+// unsubscribe is called.
+func (s *Server) Subscribe() (<-chan models.Job, func()) {
+	ch := make(chan models.Job, 64)
+	s.mu.Lock()
+	s.subscribers[ch] = true
+	s.mu.Unlock()
+
+	return ch, func() {
+		s.mu.Lock()
+		delete(s.subscribers, ch)
+		s.mu.Unlock()
+	}
+}
+
+func (s *Server) run(id string) {
+	s.mu.Lock()
+	job, ok := s.jobs[id]
+	if !ok || job.Status != models.JobQueued {
+		s.mu.Unlock()
+		return
+	}
+
+	ctx, cancel := context.WithCancelCause(s.ctx)
+	defer cancel(nil)
+
+	s.cancels[id] = cancel
+	job.Status = models.JobRunning
+	job.StartedAt = time.Now()
+	snapshot := *job
+	s.mu.Unlock()
+
+	logger.Infof("[%d] Job %s is running", job.ClassID, id)
+	s.publish(snapshot)
+
+	conf := s.conf
+	conf.UrlOrId = job.Class
+	err := services.NewSkillshare(ctx, services.WithReporter(reporter.Func(func(event models.Event) {
+		if event.Progress == nil {
+			return
+		}
+		s.update(id, func(job *models.Job) {
+			job.Progress = *event.Progress
+		})
+	}))).Run(conf)
+
+	// the cancel func is removed last, a new job of the class is only
+	// queued after this update
+	defer func() {
+		s.mu.Lock()
+		delete(s.cancels, id)
+		s.mu.Unlock()
+	}()
+
+	s.update(id, func(job *models.Job) {
+		if job.Status == models.JobCancelled {
+			return
+		}
+
+		// the interrupted job is resumed on the next start
+		if err != nil && s.ctx.Err() != nil {
+			logger.Infof("[%d] Job %s is interrupted", job.ClassID, job.ID)
+			return
+		}
+
+		job.FinishedAt = time.Now()
+		if err != nil {
+			job.Status = models.JobFailed
+			job.Error = err.Error()
+			logger.Warningf("[%d] Job %s is failed: %s", job.ClassID, job.ID, err.Error())
+			return
+		}
+
+		job.Status = models.JobDone
+		logger.Infof("[%d] Job %s is done", job.ClassID, job.ID)
+	})
+}
+
+func (s *Server) update(id string, fn func(job *models.Job)) {
+	s.mu.Lock()
+	job, ok := s.jobs[id]
+	if !ok {
+		s.mu.Unlock()
+		return
+	}
+
+	fn(job)
+	snapshot := *job
+	s.mu.Unlock()
+
+	s.publish(snapshot)
+}
+
+// publish drop the event for a subscriber which is not reading fast enough.
+func (s *Server) publish(job models.Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ch := range s.subscribers {
+		select {
+		case ch <- job:
+		default:
+		}
+	}
+}
+
+func (s *Server) persist(classID int, fn func(class *models.QueueClass)) {
+	_, err := s.queue.Update(classID, func(class *models.QueueClass) error {
+		fn(class)
+		return nil
+	})
+	if err != nil {
+		logger.Warningf("[%d] Failed update download queue: %s", classID, err.Error())
+	}
+}
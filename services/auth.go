@@ -0,0 +1,7 @@
+package services
+
+import "github.com/rizalarfiyan/skillshare-downloader/models"
+
+type Auth interface {
+	Check(conf models.Config) (*models.AuthStatus, error)
+}
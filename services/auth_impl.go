@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rizalarfiyan/skillshare-downloader/client"
+	"github.com/rizalarfiyan/skillshare-downloader/logger"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+)
+
+type auth struct {
+	ctx  context.Context
+	conf models.AppConfig
+}
+
+func NewAuth(ctx context.Context) Auth {
+	return &auth{
+		ctx: ctx,
+	}
+}
+
+func (a *auth) Check(conf models.Config) (*models.AuthStatus, error) {
+	logger.Debug("Load the cookies")
+	if err := a.conf.LoadCookies(conf); err != nil {
+		return nil, err
+	}
+
+	status := &models.AuthStatus{
+		CookieExpiry: a.conf.CookieExpiry,
+	}
+
+	logger.Debug("Do fetch user data")
+	user, err := a.fetchMeApi()
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		logger.Debug("Cookies is not logged in")
+		return status, nil
+	}
+
+	status.IsLoggedIn = true
+	status.IsPremium = user.IsMember
+	status.UserID = user.ID
+	status.Account = user.FullName
+	if user.Email != "" {
+		status.Account = fmt.Sprintf("%s <%s>", user.FullName, user.Email)
+	}
+
+	return status, nil
+}
+
+// fetchMeApi returns nil user when the cookies is not logged in.
+func (a *auth) fetchMeApi() (*models.UserData, error) {
+	logger.Debug("Send request to API user")
+	user, err := client.New(client.WithCookies(a.conf.Cookies)).GetMe(a.ctx)
+	if errors.Is(err, client.ErrUnauthorized) {
+		return nil, nil
+	}
+	return user, err
+}
@@ -0,0 +1,8 @@
+package services
+
+import "github.com/rizalarfiyan/skillshare-downloader/models"
+
+type Bundle interface {
+	Export(conf models.Config, id int, opts models.ExportOptions) (string, error)
+	Import(conf models.Config, bundlePath string, opts models.ImportOptions) (models.Manifest, error)
+}
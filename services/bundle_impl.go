@@ -0,0 +1,406 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rizalarfiyan/skillshare-downloader/bundle"
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+	"github.com/rizalarfiyan/skillshare-downloader/logger"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/utils"
+)
+
+type bundleService struct {
+	conf models.AppConfig
+}
+
+func NewBundle() Bundle {
+	return &bundleService{}
+}
+
+// Export package the downloaded class with the manifest of the file hashes
+// and returns the path of the bundle.
+func (b *bundleService) Export(conf models.Config, id int, opts models.ExportOptions) (string, error) {
+	logger.Debug("Load the config")
+	if err := b.conf.LoadLocal(conf); err != nil {
+		return "", err
+	}
+
+	if opts.Format == "" {
+		opts.Format = constants.DefaultBundleFormat
+	}
+	if opts.Format != constants.BundleZip && opts.Format != constants.BundleTarZst {
+		return "", bundle.ErrFormat
+	}
+
+	logger.Debugf("Search class directory: %s", b.conf.Dir)
+	dirs, err := findClassDirs(b.conf.Dir)
+	if err != nil {
+		return "", err
+	}
+
+	dirs, err = filterClassDirs(dirs, []int{id})
+	if err != nil {
+		return "", err
+	}
+
+	lc, err := loadLocalClass(b.conf, dirs[0].Path)
+	if err != nil {
+		return "", err
+	}
+
+	logger.Infof("[%d] Hash the files of %s", lc.class.ID, lc.class.Title)
+	manifest, err := classManifest(lc)
+	if err != nil {
+		return "", err
+	}
+
+	output := opts.Output
+	if output == "" {
+		output = fmt.Sprintf("%d_%s.%s", lc.class.ID, utils.ToSnakeCase(lc.class.Title), opts.Format)
+	}
+
+	logger.Infof("[%d] Write %d files to the bundle: %s", lc.class.ID, len(manifest.Files), output)
+	if err := writeBundle(output, opts.Format, lc.layout.base, manifest); err != nil {
+		return "", err
+	}
+
+	return output, nil
+}
+
+// Import verify the files of the bundle with its manifest and move the class
+// into the download root, the files are extracted in a temporary directory
+// first to keep the class untouched when the bundle is invalid.
+func (b *bundleService) Import(conf models.Config, bundlePath string, opts models.ImportOptions) (models.Manifest, error) {
+	logger.Debug("Load the config")
+	if err := b.conf.LoadLocal(conf); err != nil {
+		return models.Manifest{}, err
+	}
+
+	reader, err := bundle.Open(bundlePath)
+	if err != nil {
+		return models.Manifest{}, err
+	}
+	defer reader.Close()
+
+	manifest, err := readManifest(reader)
+	if err != nil {
+		return manifest, err
+	}
+
+	dest := filepath.Join(b.conf.Dir, manifest.Dir)
+	if utils.IsExistPath(dest) && !opts.IsForce {
+		return manifest, fmt.Errorf("%s: %w, use --force to replace it", dest, ErrClassExists)
+	}
+
+	if err := utils.CreateDir(b.conf.Dir); err != nil {
+		return manifest, err
+	}
+
+	tmp, err := os.MkdirTemp(b.conf.Dir, constants.FolderImport)
+	if err != nil {
+		return manifest, err
+	}
+	defer os.RemoveAll(tmp)
+
+	logger.Infof("[%d] Extract and verify %d files of %s", manifest.ClassID, len(manifest.Files), manifest.Title)
+	staging := filepath.Join(tmp, manifest.Dir)
+	if err := extractBundle(reader, manifest, staging); err != nil {
+		return manifest, err
+	}
+
+	lc, err := loadLocalClass(b.conf, staging)
+	if err != nil {
+		return manifest, fmt.Errorf("invalid class data: %w", err)
+	}
+	if lc.class.ID != manifest.ClassID {
+		return manifest, fmt.Errorf("class data is class %d instead of %d", lc.class.ID, manifest.ClassID)
+	}
+
+	if err := replaceDir(staging, dest, tmp); err != nil {
+		return manifest, err
+	}
+
+	if err := indexClass(b.conf, dest); err != nil {
+		logger.Warningf("Failed update the library index: %s", err.Error())
+	}
+
+	return manifest, nil
+}
+
+// classManifest hash every file of the class directory.
+func classManifest(lc *localClass) (models.Manifest, error) {
+	manifest := models.Manifest{
+		Version:   constants.BundleVersion,
+		ClassID:   lc.class.ID,
+		Title:     lc.class.Title,
+		Teacher:   lc.class.Teacher,
+		Dir:       filepath.Base(lc.layout.base),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	err := filepath.WalkDir(lc.layout.base, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		if !entry.Type().IsRegular() {
+			logger.Debugf("Skip %s, not a regular file", filePath)
+			return nil
+		}
+
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		hash := sha256.New()
+		size, err := io.Copy(hash, file)
+		if err != nil {
+			return err
+		}
+
+		manifest.Files = append(manifest.Files, models.ManifestFile{
+			Path:   filepath.ToSlash(relativePath(lc.layout.base, filePath)),
+			Size:   size,
+			SHA256: hex.EncodeToString(hash.Sum(nil)),
+		})
+		return nil
+	})
+
+	return manifest, err
+}
+
+// writeBundle write the manifest first then every file inside the directory
+// of the class, the bundle is renamed to the output once complete.
+func writeBundle(output, format, base string, manifest models.Manifest) error {
+	tmpFile := output + ".part"
+	file, err := os.Create(tmpFile)
+	if err != nil {
+		return err
+	}
+
+	err = func() error {
+		writer, err := bundle.NewWriter(file, format)
+		if err != nil {
+			return err
+		}
+
+		value, err := json.MarshalIndent(manifest, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		w, err := writer.Create(constants.FilenameManifest, int64(len(value)), manifest.CreatedAt)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(value); err != nil {
+			return err
+		}
+
+		for _, item := range manifest.Files {
+			logger.Debugf("Add to the bundle: %s", item.Path)
+			if err := addBundleFile(writer, base, manifest.Dir, item); err != nil {
+				return err
+			}
+		}
+
+		return writer.Close()
+	}()
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpFile)
+		return err
+	}
+
+	return os.Rename(tmpFile, output)
+}
+
+func addBundleFile(writer bundle.Writer, base, dir string, item models.ManifestFile) error {
+	file, err := os.Open(filepath.Join(base, filepath.FromSlash(item.Path)))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	w, err := writer.Create(path.Join(dir, item.Path), item.Size, info.ModTime())
+	if err != nil {
+		return err
+	}
+
+	// the file is hashed again, it must not change after the manifest
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(w, hash), io.LimitReader(file, item.Size))
+	if err != nil {
+		return err
+	}
+
+	if size != item.Size || hex.EncodeToString(hash.Sum(nil)) != item.SHA256 {
+		return fmt.Errorf("%s changed while exporting: %w", item.Path, ErrHashMismatch)
+	}
+	return nil
+}
+
+// readManifest read the manifest, it is the first entry of the bundle.
+func readManifest(reader bundle.Reader) (models.Manifest, error) {
+	var manifest models.Manifest
+	entry, r, err := reader.Next()
+	if errors.Is(err, io.EOF) || (err == nil && entry.Name != constants.FilenameManifest) {
+		return manifest, ErrNoManifest
+	}
+	if err != nil {
+		return manifest, err
+	}
+
+	if err := json.NewDecoder(r).Decode(&manifest); err != nil {
+		return manifest, fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	if manifest.Version > constants.BundleVersion {
+		return manifest, fmt.Errorf("bundle version %d is not supported, update the downloader", manifest.Version)
+	}
+
+	match := regexClassDir.FindStringSubmatch(manifest.Dir)
+	if len(match) < 2 || match[1] != strconv.Itoa(manifest.ClassID) || !filepath.IsLocal(manifest.Dir) || filepath.Base(manifest.Dir) != manifest.Dir {
+		return manifest, fmt.Errorf("invalid manifest: class directory %q", manifest.Dir)
+	}
+
+	hasClassData := false
+	for _, file := range manifest.Files {
+		if path.Clean(file.Path) != file.Path || !filepath.IsLocal(filepath.FromSlash(file.Path)) {
+			return manifest, fmt.Errorf("invalid manifest: file path %q", file.Path)
+		}
+		hasClassData = hasClassData || file.Path == path.Join("json", constants.FilenameClassData)
+	}
+
+	if !hasClassData {
+		return manifest, fmt.Errorf("invalid manifest: no %s", constants.FilenameClassData)
+	}
+
+	return manifest, nil
+}
+
+// extractBundle write the files of the bundle into the directory, every file
+// must be in the manifest with the same hash.
+func extractBundle(reader bundle.Reader, manifest models.Manifest, dir string) error {
+	files := make(map[string]models.ManifestFile, len(manifest.Files))
+	for _, file := range manifest.Files {
+		files[file.Path] = file
+	}
+
+	seen := make(map[string]bool, len(files))
+	prefix := manifest.Dir + "/"
+	for {
+		entry, r, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if entry.Mode.IsDir() {
+			continue
+		}
+
+		name := strings.TrimPrefix(entry.Name, prefix)
+		item, ok := files[name]
+		if !ok || name == entry.Name || seen[name] {
+			return fmt.Errorf("%s is not in the manifest", entry.Name)
+		}
+		if !entry.Mode.IsRegular() {
+			return fmt.Errorf("%s is not a regular file", entry.Name)
+		}
+		seen[name] = true
+
+		logger.Debugf("Extract from the bundle: %s", name)
+		if err := extractBundleFile(r, item, entry.ModTime, filepath.Join(dir, filepath.FromSlash(name))); err != nil {
+			return err
+		}
+	}
+
+	for _, file := range manifest.Files {
+		if !seen[file.Path] {
+			return fmt.Errorf("%s is missing in the bundle", file.Path)
+		}
+	}
+
+	return nil
+}
+
+func extractBundleFile(r io.Reader, item models.ManifestFile, modTime time.Time, target string) error {
+	if err := utils.CreateDir(filepath.Dir(target)); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	// one more byte to find the file bigger than the manifest
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(file, hash), io.LimitReader(r, item.Size+1))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if size != item.Size || hex.EncodeToString(hash.Sum(nil)) != item.SHA256 {
+		return fmt.Errorf("%s: %w", item.Path, ErrHashMismatch)
+	}
+
+	if !modTime.IsZero() {
+		return os.Chtimes(target, modTime, modTime)
+	}
+	return nil
+}
+
+// replaceDir move the directory to dest, the previous dest is moved into tmp
+// and put back when the move failed.
+func replaceDir(dir, dest, tmp string) error {
+	if !utils.IsExistPath(dest) {
+		return os.Rename(dir, dest)
+	}
+
+	previous := filepath.Join(tmp, "previous")
+	if err := os.Rename(dest, previous); err != nil {
+		return err
+	}
+
+	if err := os.Rename(dir, dest); err != nil {
+		if restoreErr := os.Rename(previous, dest); restoreErr != nil {
+			logger.Warningf("Failed restore %s: %s", dest, restoreErr.Error())
+		}
+		return err
+	}
+	return nil
+}
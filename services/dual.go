@@ -0,0 +1,111 @@
+package services
+
+import (
+	"errors"
+	"os"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+	"github.com/rizalarfiyan/skillshare-downloader/logger"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/subtitle"
+)
+
+// isDualSub returns true for the subtitles merged into the dual subtitles.
+func (s *skillshare) isDualSub(video models.SkillshareVideo, sub models.SkillshareVideoSubtitle) bool {
+	for _, lang := range s.conf.DualSubs {
+		if match, ok := matchSubtitle(video.Subtitles, lang); ok && match.Src == sub.Src {
+			return true
+		}
+	}
+	return false
+}
+
+// createDualSubtitles merge the downloaded subtitles of both languages of
+// every lesson, the lessons without both languages are skipped.
+func (s *skillshare) createDualSubtitles(ss models.SkillshareClass) error {
+	if len(s.conf.DualSubs) == 0 {
+		return nil
+	}
+
+	count := 0
+	for idx, val := range ss.Videos {
+		if !s.isSelected(val.ID) {
+			continue
+		}
+
+		primary, okPrimary := matchSubtitle(val.Subtitles, s.conf.DualSubs[0])
+		secondary, okSecondary := matchSubtitle(val.Subtitles, s.conf.DualSubs[1])
+		if !okPrimary || !okSecondary {
+			logger.Infof("[%d] %s has no subtitle %s and %s, skip dual subtitles", val.ID, val.Title, s.conf.DualSubs[0], s.conf.DualSubs[1])
+			continue
+		}
+
+		primaryPath, err := s.layout().subtitlePath(ss, idx, primary)
+		if err != nil {
+			return err
+		}
+
+		secondaryPath, err := s.layout().subtitlePath(ss, idx, secondary)
+		if err != nil {
+			return err
+		}
+
+		primaryCues, err := subtitle.ParseVTTFile(primaryPath)
+		if err != nil {
+			logDualError(val, err)
+			continue
+		}
+
+		secondaryCues, err := subtitle.ParseVTTFile(secondaryPath)
+		if err != nil {
+			logDualError(val, err)
+			continue
+		}
+
+		drift := subtitle.Drift(primaryCues, secondaryCues)
+		if drift != 0 {
+			logger.Debugf("[%d] Shift subtitle %s by %s", val.ID, secondary.Lang, drift)
+		}
+
+		filePath := s.layout().dualSubtitlePath(primaryPath, secondary.Lang)
+		logger.Debugf("[%d] Write dual subtitles to file: %s", val.ID, filePath)
+		err = writeDualSubtitles(filePath, val.Title, subtitle.Merge(primaryCues, secondaryCues), s.conf.DualSubsFormat)
+		if err != nil {
+			return err
+		}
+
+		s.recordFile(val.ID, func(lesson *models.LessonFiles) {
+			lesson.SetDual(secondary.Lang, s.classFile(filePath))
+		})
+		count++
+	}
+
+	logger.Infof("Create dual subtitles of %d lessons done", count)
+	return nil
+}
+
+func writeDualSubtitles(filePath, title string, cues []subtitle.DualCue, format string) error {
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if format == constants.DualSubsASS {
+		err = subtitle.WriteDualASS(file, title, cues)
+	} else {
+		err = subtitle.WriteDualVTT(file, cues)
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func logDualError(val models.SkillshareVideo, err error) {
+	if errors.Is(err, os.ErrNotExist) {
+		logger.Debugf("[%d] Subtitle is not downloaded: %s", val.ID, err.Error())
+		return
+	}
+	logger.Warningf("[%d] Failed read the subtitle: %s", val.ID, err.Error())
+}
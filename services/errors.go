@@ -0,0 +1,17 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/rizalarfiyan/skillshare-downloader/client"
+)
+
+var (
+	ErrUnauthorized  = client.ErrUnauthorized
+	ErrRateLimited   = client.ErrRateLimited
+	ErrNotDownloaded = errors.New("class is not downloaded")
+	ErrCancelled     = errors.New("download is cancelled")
+	ErrNoManifest    = errors.New("bundle has no manifest")
+	ErrHashMismatch  = errors.New("file hash does not match the manifest")
+	ErrClassExists   = errors.New("class is already in the download root")
+)
@@ -0,0 +1,7 @@
+package services
+
+import "github.com/rizalarfiyan/skillshare-downloader/models"
+
+type Feed interface {
+	Run(conf models.Config, ids []int, opts models.FeedOptions) ([]string, error)
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+	"github.com/rizalarfiyan/skillshare-downloader/logger"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/podcast"
+)
+
+type feed struct {
+	conf models.AppConfig
+	opts models.FeedOptions
+}
+
+func NewFeed() Feed {
+	return &feed{}
+}
+
+// Run write the podcast feed of the downloaded classes and returns the path
+// of the written feeds, every class in the download root is used when ids is
+// empty.
+func (f *feed) Run(conf models.Config, ids []int, opts models.FeedOptions) ([]string, error) {
+	logger.Debug("Load the config")
+	if err := f.conf.LoadLocal(conf); err != nil {
+		return nil, err
+	}
+	f.opts = opts
+
+	logger.Debugf("Search class directory: %s", f.conf.Dir)
+	dirs, err := findClassDirs(f.conf.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	dirs, err = filterClassDirs(dirs, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	library := podcast.Channel{
+		Title:    constants.FeedLibraryTitle,
+		Category: constants.FeedCategory,
+		Language: f.conf.Lang,
+		IsSerial: true,
+	}
+
+	var paths []string
+	season := 0
+	for _, dir := range dirs {
+		lc, err := loadLocalClass(f.conf, dir.Path)
+		if err != nil {
+			logger.Warningf("Skip class directory %s: %s", dir.Path, err.Error())
+			continue
+		}
+
+		channel := f.classChannel(lc)
+		if !opts.IsLibrary {
+			filePath := filepath.Join(lc.layout.base, constants.FilenameFeed)
+			if err := writeFeed(filePath, channel); err != nil {
+				return paths, err
+			}
+
+			logger.Infof("[%d] Feed of %s with %d episodes", lc.class.ID, lc.class.Title, len(channel.Episodes))
+			paths = append(paths, filePath)
+			continue
+		}
+
+		// every class is a season of the library
+		season++
+		for _, episode := range channel.Episodes {
+			episode.Title = fmt.Sprintf("%s: %s", lc.class.Title, episode.Title)
+			episode.Season = season
+			library.Episodes = append(library.Episodes, episode)
+		}
+		if library.Image == "" {
+			library.Image = channel.Image
+		}
+	}
+
+	if !opts.IsLibrary {
+		return paths, nil
+	}
+
+	library.Description = fmt.Sprintf("%d lessons of the classes downloaded with skillshare downloader", len(library.Episodes))
+	filePath := filepath.Join(f.conf.Dir, constants.FilenameFeed)
+	if err := writeFeed(filePath, library); err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Feed of the library with %d episodes", len(library.Episodes))
+	return []string{filePath}, nil
+}
+
+// classChannel returns the podcast of the class with the downloaded lessons
+// as episodes.
+func (f *feed) classChannel(lc *localClass) podcast.Channel {
+	channel := podcast.Channel{
+		Title:       lc.class.Title,
+		Link:        lc.data.WebURL,
+		Description: lc.class.Description,
+		Language:    f.conf.Lang,
+		Author:      lc.class.Teacher,
+		Image:       lc.class.ImageHuge,
+		Category:    constants.FeedCategory,
+		IsSerial:    true,
+	}
+
+	// the class data has no description when the api does not send it
+	if channel.Description == "" {
+		channel.Description = fmt.Sprintf("%s by %s", lc.class.Title, lc.class.Teacher)
+		if lc.class.ProjectTitle != "" {
+			channel.Description += fmt.Sprintf(", class project: %s", lc.class.ProjectTitle)
+		}
+	}
+
+	// the lessons are downloaded in a few seconds, the podcast apps need a
+	// different date to keep the order
+	var published time.Time
+	for idx, video := range lc.class.Videos {
+		source, ok := lc.layout.source(video)
+		if !ok {
+			continue
+		}
+
+		filePath, err := lc.layout.videoPath(lc.class, idx, source)
+		info, ok := statFile(filePath, err)
+		if !ok {
+			continue
+		}
+
+		published = info.ModTime().Truncate(time.Second)
+		if last := len(channel.Episodes); last > 0 && !published.After(channel.Episodes[last-1].Published) {
+			published = channel.Episodes[last-1].Published.Add(time.Second)
+		}
+
+		channel.Episodes = append(channel.Episodes, podcast.Episode{
+			GUID:        fmt.Sprintf("skillshare-%d-%d", lc.class.ID, video.ID),
+			Title:       video.Title,
+			Description: lessonNotes(lc, idx),
+			URL:         f.mediaURL(filePath),
+			Type:        podcast.MediaType(filePath),
+			Length:      info.Size(),
+			Duration:    lessonDuration(lc, idx),
+			Published:   published,
+			Number:      idx + 1,
+			Image:       lc.class.ImageHuge,
+		})
+	}
+
+	return channel
+}
+
+// mediaURL returns the url of the file served from the download root.
+func (f *feed) mediaURL(filePath string) string {
+	return podcast.URL(f.opts.BaseURL, relativePath(f.conf.Dir, filePath))
+}
+
+func lessonNotes(lc *localClass, idx int) string {
+	video := lc.class.Videos[idx]
+	if data := lc.videos[idx]; data != nil {
+		if notes := models.TextValue(data.LongDescription); notes != "" {
+			return notes
+		}
+		if notes := models.TextValue(data.Description); notes != "" {
+			return notes
+		}
+	}
+
+	notes := fmt.Sprintf("Lesson %d of %s", idx+1, lc.class.Title)
+	if video.UnitTitle != "" {
+		notes += fmt.Sprintf(", %s", video.UnitTitle)
+	}
+	return notes
+}
+
+func lessonDuration(lc *localClass, idx int) time.Duration {
+	if seconds := lc.class.Videos[idx].VideoDurationSeconds; seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if data := lc.videos[idx]; data != nil {
+		return time.Duration(data.Duration) * time.Millisecond
+	}
+	return 0
+}
+
+func writeFeed(filePath string, channel podcast.Channel) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+
+	err = podcast.Write(file, channel, fmt.Sprintf("skillshare downloader %s", constants.AppVersion))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/utils"
+)
+
+// classLayout resolve the path of every file inside a class directory, it is
+// shared by the download and the commands working with downloaded classes.
+// The files written by the download are used first when they are loaded,
+// the templates are only rendered for the lessons without them.
+type classLayout struct {
+	conf  models.AppConfig
+	base  string
+	json  string
+	video string
+	files *models.ClassFiles
+	paths *pathCache
+}
+
+func newClassLayout(conf models.AppConfig, base string) classLayout {
+	return classLayout{
+		conf:  conf,
+		base:  base,
+		json:  path.Join(base, "json"),
+		video: path.Join(base, "video"),
+		paths: newPathCache(),
+	}
+}
+
+// pathCache keep the rendered paths of every lesson, the paths of a class
+// are rendered once for the same template and the same fields.
+type pathCache struct {
+	mu    sync.Mutex
+	paths map[pathKey][]resolvedPath
+}
+
+type pathKey struct {
+	videos *models.SkillshareVideo
+	count  int
+	tmpl   *template.Template
+	dir    string
+	layout string
+	fields models.TemplateData
+}
+
+type resolvedPath struct {
+	path string
+	err  error
+}
+
+func newPathCache() *pathCache {
+	return &pathCache{paths: make(map[pathKey][]resolvedPath)}
+}
+
+func (c *pathCache) get(key pathKey, resolve func() []resolvedPath) []resolvedPath {
+	if c == nil {
+		return resolve()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	paths, ok := c.paths[key]
+	if !ok {
+		paths = resolve()
+		c.paths[key] = paths
+	}
+	return paths
+}
+
+func (l classLayout) lessonDir(video models.SkillshareVideo) string {
+	if l.conf.Layout != constants.LayoutUnits || video.UnitNumber == 0 {
+		return l.video
+	}
+
+	unitName := fmt.Sprintf(constants.FolderUnit, video.UnitNumber, utils.SafeNameOr(video.UnitTitle, strconv.Itoa(video.UnitID)))
+	return path.Join(l.video, unitName)
+}
+
+func (l classLayout) classDataPath() string {
+	return path.Join(l.json, constants.FilenameClassData)
+}
+
+func (l classLayout) videoDataPath(idx int, video models.SkillshareVideo) string {
+	if lesson := l.files.Lesson(video.ID); lesson != nil && lesson.Data != "" {
+		return path.Join(l.base, lesson.Data)
+	}
+
+	filename := fmt.Sprintf(constants.FilenameVideoData, idx+1, utils.ToSnakeCase(lessonTitle(video)))
+	return path.Join(l.json, filename)
+}
+
+// source returns the source to download, the smallest one in audio only
+// mode because only the audio is kept. The source of a written video is the
+// one it is downloaded from.
+func (l classLayout) source(video models.SkillshareVideo) (models.SkillshareVideoSource, bool) {
+	if lesson := l.files.Lesson(video.ID); lesson != nil && lesson.Video != "" {
+		if lesson.IsAudioOnly {
+			return video.SelectAudioSource()
+		}
+		return video.SelectSource(lesson.Height)
+	}
+
+	if l.conf.IsAudioOnly {
+		return video.SelectAudioSource()
+	}
+	return video.SelectSource(l.conf.Quality)
+}
+
+// isAudioOnly returns true when only the audio of the lesson is kept.
+func (l classLayout) isAudioOnly(video models.SkillshareVideo) bool {
+	if lesson := l.files.Lesson(video.ID); lesson != nil && lesson.Video != "" {
+		return lesson.IsAudioOnly
+	}
+	return l.conf.IsAudioOnly
+}
+
+func (l classLayout) videoPath(ss models.SkillshareClass, idx int, source models.SkillshareVideoSource) (string, error) {
+	if lesson := l.files.Lesson(ss.Videos[idx].ID); lesson != nil && lesson.Video != "" {
+		return path.Join(l.base, lesson.Video), nil
+	}
+
+	return l.uniquePath(ss, idx, l.conf.OutputTemplate, func(data *models.TemplateData) {
+		data.Height = source.Height
+		data.Lang = l.conf.Lang
+		data.Ext = utils.MatchExtenstion(source.Src, fmt.Sprintf(".%s", strings.ToLower(source.Container)))
+		if l.conf.IsAudioOnly {
+			data.Ext = constants.ExtAudio
+		}
+	})
+}
+
+func (l classLayout) subtitlePath(ss models.SkillshareClass, idx int, sub models.SkillshareVideoSubtitle) (string, error) {
+	if filePath, ok := l.files.Lesson(ss.Videos[idx].ID).Subtitle(sub.Lang); ok {
+		return path.Join(l.base, filePath), nil
+	}
+
+	return l.uniquePath(ss, idx, l.conf.SubtitleTemplate, func(data *models.TemplateData) {
+		data.Lang = sub.Lang
+		data.Ext = utils.MatchExtenstion(sub.Src, ".vtt")
+	})
+}
+
+// uniquePath render the filename of the lesson, when an earlier lesson has
+// the same path a number is added, e.g. intro_2.mp4. The comparison ignore
+// the case for the case insensitive filesystems.
+func (l classLayout) uniquePath(ss models.SkillshareClass, idx int, tmpl *template.Template, fill func(data *models.TemplateData)) (string, error) {
+	var fields models.TemplateData
+	fill(&fields)
+
+	key := pathKey{
+		tmpl:   tmpl,
+		dir:    l.video,
+		layout: l.conf.Layout,
+		count:  len(ss.Videos),
+		fields: fields,
+	}
+	if len(ss.Videos) > 0 {
+		key.videos = &ss.Videos[0]
+	}
+
+	paths := l.paths.get(key, func() []resolvedPath {
+		return l.resolvePaths(ss, tmpl, fill)
+	})
+	return paths[idx].path, paths[idx].err
+}
+
+// resolvePaths render the template once for every lesson of the class and
+// add the counter to the paths used by an earlier lesson.
+func (l classLayout) resolvePaths(ss models.SkillshareClass, tmpl *template.Template, fill func(data *models.TemplateData)) []resolvedPath {
+	paths := make([]resolvedPath, len(ss.Videos))
+	counts := make(map[string]int)
+	for idx := range ss.Videos {
+		data := templateData(ss, idx)
+		fill(&data)
+		fileName, err := models.RenderFilename(tmpl, data)
+		if err != nil {
+			paths[idx].err = err
+			continue
+		}
+
+		filePath := path.Join(l.lessonDir(ss.Videos[idx]), fileName)
+		key := strings.ToLower(filePath)
+		counts[key]++
+		if counts[key] > 1 {
+			ext := path.Ext(filePath)
+			filePath = fmt.Sprintf("%s_%d%s", strings.TrimSuffix(filePath, ext), counts[key], ext)
+		}
+		paths[idx].path = filePath
+	}
+	return paths
+}
+
+// transcriptPath returns the transcript written next to the subtitle.
+func (l classLayout) transcriptPath(subPath, format string) string {
+	return strings.TrimSuffix(subPath, path.Ext(subPath)) + "." + format
+}
+
+// dualSubtitlePath returns the dual subtitles written next to the subtitle
+// of the primary language, e.g. 001_intro.en-us.es.vtt.
+func (l classLayout) dualSubtitlePath(subPath, lang string) string {
+	return fmt.Sprintf("%s.%s.%s", strings.TrimSuffix(subPath, path.Ext(subPath)), strings.ToLower(lang), l.conf.DualSubsFormat)
+}
+
+// Role of the lesson files, the files of a lesson are matched by their role
+// when the lesson moves.
+const (
+	roleData  = "data"
+	roleVideo = "video"
+)
+
+func roleSubtitle(lang string) string {
+	return "subtitle." + strings.ToLower(lang)
+}
+
+func roleTranscript(lang, format string) string {
+	return "transcript." + strings.ToLower(lang) + "." + format
+}
+
+func roleDual(lang string) string {
+	return "dual." + strings.ToLower(lang)
+}
+
+// lessonFiles returns every file path of the lesson by role, the video and
+// subtitles need the sources of the lesson, the transcripts of both formats
+// are listed next to their subtitle. The languages sharing one subtitle file
+// only list it once.
+func (l classLayout) lessonFiles(ss models.SkillshareClass, idx int) (map[string]string, error) {
+	video := ss.Videos[idx]
+	files := map[string]string{roleData: l.videoDataPath(idx, video)}
+	if source, ok := l.source(video); ok {
+		filePath, err := l.videoPath(ss, idx, source)
+		if err != nil {
+			return nil, err
+		}
+		files[roleVideo] = filePath
+	}
+
+	seen := make(map[string]bool)
+	for _, sub := range video.Subtitles {
+		filePath, err := l.subtitlePath(ss, idx, sub)
+		if err != nil {
+			return nil, err
+		}
+
+		if seen[filePath] {
+			continue
+		}
+
+		seen[filePath] = true
+		files[roleSubtitle(sub.Lang)] = filePath
+		for _, format := range []string{constants.TranscriptMarkdown, constants.TranscriptText} {
+			files[roleTranscript(sub.Lang, format)] = l.transcriptPath(filePath, format)
+		}
+	}
+
+	if lesson := l.files.Lesson(video.ID); lesson != nil && len(lesson.Dual) > 0 {
+		for lang, filePath := range lesson.Dual {
+			files[roleDual(lang)] = path.Join(l.base, filePath)
+		}
+	} else if len(l.conf.DualSubs) > 0 {
+		primary, okPrimary := matchSubtitle(video.Subtitles, l.conf.DualSubs[0])
+		secondary, okSecondary := matchSubtitle(video.Subtitles, l.conf.DualSubs[1])
+		if okPrimary && okSecondary {
+			filePath, err := l.subtitlePath(ss, idx, primary)
+			if err != nil {
+				return nil, err
+			}
+			files[roleDual(secondary.Lang)] = l.dualSubtitlePath(filePath, secondary.Lang)
+		}
+	}
+
+	return files, nil
+}
+
+// setLessonFile record the file of the role, the transcripts are not
+// recorded because they are always next to their subtitle.
+func setLessonFile(lesson *models.LessonFiles, role, filePath string) {
+	switch {
+	case role == roleData:
+		lesson.Data = filePath
+	case role == roleVideo:
+		lesson.Video = filePath
+	case strings.HasPrefix(role, "subtitle."):
+		lesson.SetSubtitle(strings.TrimPrefix(role, "subtitle."), filePath)
+	case strings.HasPrefix(role, "dual."):
+		lesson.SetDual(strings.TrimPrefix(role, "dual."), filePath)
+	}
+}
+
+func templateData(ss models.SkillshareClass, idx int) models.TemplateData {
+	video := ss.Videos[idx]
+	return models.TemplateData{
+		Class: models.TemplateClass{
+			ID:    ss.ID,
+			Title: ss.Title,
+		},
+		Unit: models.TemplateUnit{
+			Number: video.UnitNumber,
+			Title:  video.UnitTitle,
+		},
+		Lesson: models.TemplateLesson{
+			ID:    video.ID,
+			Title: lessonTitle(video),
+		},
+		Teacher:  ss.Teacher,
+		Category: ss.Category,
+		Index:    idx + 1,
+	}
+}
+
+// lessonTitle returns the session id when the title has nothing left for a
+// filename, e.g. only emoji.
+func lessonTitle(video models.SkillshareVideo) string {
+	if utils.ToSnakeCase(video.Title) != "" {
+		return video.Title
+	}
+
+	if video.SessionID != 0 {
+		return strconv.Itoa(video.SessionID)
+	}
+	return strconv.Itoa(video.ID)
+}
@@ -0,0 +1,8 @@
+package services
+
+import "github.com/rizalarfiyan/skillshare-downloader/models"
+
+type Library interface {
+	Rebuild(conf models.Config) (int, error)
+	ClassIDs(conf models.Config) ([]int, error)
+}
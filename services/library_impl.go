@@ -0,0 +1,94 @@
+package services
+
+import (
+	"github.com/rizalarfiyan/skillshare-downloader/library"
+	"github.com/rizalarfiyan/skillshare-downloader/logger"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+)
+
+type libraryService struct {
+	conf models.AppConfig
+}
+
+func NewLibrary() Library {
+	return &libraryService{}
+}
+
+// Rebuild rescan every class directory in the download root and returns the
+// number of indexed classes.
+func (l *libraryService) Rebuild(conf models.Config) (int, error) {
+	logger.Debug("Load the config")
+	if err := l.conf.LoadLocal(conf); err != nil {
+		return 0, err
+	}
+
+	logger.Debugf("Search class directory: %s", l.conf.Dir)
+	dirs, err := findClassDirs(l.conf.Dir)
+	if err != nil {
+		return 0, err
+	}
+
+	lib, err := library.Open(l.conf.Dir)
+	if err != nil {
+		return 0, err
+	}
+	defer lib.Close()
+
+	logger.Debug("Reset library index")
+	if err := lib.Reset(); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, dir := range dirs {
+		lc, err := loadLocalClass(l.conf, dir.Path)
+		if err != nil {
+			logger.Warningf("Skip class directory %s: %s", dir.Path, err.Error())
+			continue
+		}
+
+		err = lib.Put(lc.libraryClass(l.conf.Dir))
+		if err != nil {
+			return count, err
+		}
+
+		logger.Infof("[%d] Indexed %s", lc.class.ID, lc.class.Title)
+		count++
+	}
+
+	return count, nil
+}
+
+// ClassIDs returns the id of every class directory in the download root.
+func (l *libraryService) ClassIDs(conf models.Config) ([]int, error) {
+	if err := l.conf.LoadLocal(conf); err != nil {
+		return nil, err
+	}
+
+	dirs, err := findClassDirs(l.conf.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, len(dirs))
+	for idx, dir := range dirs {
+		ids[idx] = dir.ID
+	}
+	return ids, nil
+}
+
+// indexClass update the library index for one class directory.
+func indexClass(conf models.AppConfig, base string) error {
+	lc, err := loadLocalClass(conf, base)
+	if err != nil {
+		return err
+	}
+
+	lib, err := library.Open(conf.Dir)
+	if err != nil {
+		return err
+	}
+	defer lib.Close()
+
+	return lib.Put(lc.libraryClass(conf.Dir))
+}
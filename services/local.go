@@ -0,0 +1,263 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+	"github.com/rizalarfiyan/skillshare-downloader/logger"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/utils"
+)
+
+var regexClassDir = regexp.MustCompile(`^\[(\d+)\]`)
+
+// localClass is a downloaded class read from the cached json in its
+// directory, without any request to the api.
+type localClass struct {
+	layout classLayout
+	data   models.ClassData
+	class  models.SkillshareClass
+	videos []*models.VideoData
+}
+
+type classDir struct {
+	ID   int
+	Path string
+}
+
+// findClassDirs returns the class directories inside root which have the
+// cached class data.
+func findClassDirs(root string) ([]classDir, error) {
+	if !utils.IsExistPath(root) {
+		return nil, nil
+	}
+
+	names, err := utils.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(names)
+	var dirs []classDir
+	for _, name := range names {
+		match := regexClassDir.FindStringSubmatch(name)
+		if len(match) < 2 {
+			continue
+		}
+
+		base := filepath.Join(root, name)
+		if !utils.IsExistPath(filepath.Join(base, "json")) {
+			continue
+		}
+
+		id, _ := strconv.Atoi(match[1])
+		dirs = append(dirs, classDir{ID: id, Path: base})
+	}
+
+	return dirs, nil
+}
+
+func loadLocalClass(conf models.AppConfig, base string) (*localClass, error) {
+	lc := &localClass{
+		layout: newClassLayout(conf, base),
+	}
+
+	logger.Debugf("Load local class: %s", base)
+	data, err := os.ReadFile(lc.layout.classDataPath())
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &lc.data)
+	if err != nil {
+		return nil, err
+	}
+
+	lc.class = lc.data.Mapper()
+	lc.layout.files, err = readClassFiles(lc.layout.json)
+	if err != nil {
+		return nil, err
+	}
+
+	lc.videos = make([]*models.VideoData, len(lc.class.Videos))
+	for idx := range lc.class.Videos {
+		fileJson := lc.layout.videoDataPath(idx, lc.class.Videos[idx])
+		if !utils.IsExistPath(fileJson) {
+			logger.Debugf("[%d] No video data in local class", lc.class.Videos[idx].ID)
+			continue
+		}
+
+		data, err := os.ReadFile(fileJson)
+		if err != nil {
+			return nil, err
+		}
+
+		video := &models.VideoData{}
+		err = json.Unmarshal(data, video)
+		if err != nil {
+			return nil, err
+		}
+
+		lc.videos[idx] = video
+		lc.class.Videos[idx].AddSourceSubtitle(*video)
+	}
+
+	return lc, nil
+}
+
+// readClassFiles returns nil for the classes downloaded before the files
+// are recorded.
+func readClassFiles(jsonDir string) (*models.ClassFiles, error) {
+	data, err := os.ReadFile(filepath.Join(jsonDir, constants.FilenameClassFiles))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	files := &models.ClassFiles{}
+	if err := json.Unmarshal(data, files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func writeClassFiles(jsonDir string, files *models.ClassFiles) error {
+	value, err := json.MarshalIndent(files, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	fileJson := filepath.Join(jsonDir, constants.FilenameClassFiles)
+	tmpFile := fileJson + ".tmp"
+	if err := os.WriteFile(tmpFile, value, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, fileJson)
+}
+
+// libraryClass stat the downloaded files of the class, the path is relative
+// to root.
+func (lc *localClass) libraryClass(root string) models.LibraryClass {
+	class := models.LibraryClass{
+		ID:        lc.class.ID,
+		Title:     lc.class.Title,
+		Teacher:   lc.class.Teacher,
+		Category:  lc.class.Category,
+		Dir:       relativePath(root, lc.layout.base),
+		IndexedAt: time.Now(),
+	}
+
+	languages := make(map[string]bool)
+	for idx, video := range lc.class.Videos {
+		lesson := models.LibraryLesson{
+			Index:    idx + 1,
+			ID:       video.ID,
+			Title:    video.Title,
+			Unit:     video.UnitTitle,
+			Duration: video.VideoDuration,
+		}
+
+		if source, ok := lc.layout.source(video); ok {
+			filePath, err := lc.layout.videoPath(lc.class, idx, source)
+			if info, ok := statFile(filePath, err); ok {
+				lesson.Path = relativePath(root, filePath)
+				lesson.Size = info.Size()
+				lesson.DownloadedAt = info.ModTime()
+			}
+		}
+
+		// every language share the same file when the template has no lang,
+		// the configured language is the one downloaded there.
+		seen := make(map[string]bool)
+		for _, sub := range lc.preferLanguage(video.Subtitles) {
+			filePath, err := lc.layout.subtitlePath(lc.class, idx, sub)
+			if seen[filePath] {
+				continue
+			}
+			seen[filePath] = true
+			if info, ok := statFile(filePath, err); ok {
+				lesson.Subtitles = append(lesson.Subtitles, models.LibrarySubtitle{
+					Lang: sub.Lang,
+					Path: relativePath(root, filePath),
+					Size: info.Size(),
+				})
+				languages[sub.Lang] = true
+			}
+		}
+
+		class.Size += lesson.Size
+		for _, sub := range lesson.Subtitles {
+			class.Size += sub.Size
+		}
+		if lesson.DownloadedAt.After(class.DownloadedAt) {
+			class.DownloadedAt = lesson.DownloadedAt
+		}
+		class.Lessons = append(class.Lessons, lesson)
+	}
+
+	for lang := range languages {
+		class.Languages = append(class.Languages, lang)
+	}
+	sort.Strings(class.Languages)
+
+	return class
+}
+
+func (lc *localClass) preferLanguage(subtitles []models.SkillshareVideoSubtitle) []models.SkillshareVideoSubtitle {
+	sorted := make([]models.SkillshareVideoSubtitle, len(subtitles))
+	copy(sorted, subtitles)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return strings.EqualFold(sorted[i].Lang, lc.layout.conf.Lang) && !strings.EqualFold(sorted[j].Lang, lc.layout.conf.Lang)
+	})
+	return sorted
+}
+
+func statFile(filePath string, err error) (os.FileInfo, bool) {
+	if err != nil {
+		return nil, false
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil || info.IsDir() {
+		return nil, false
+	}
+
+	return info, true
+}
+
+func relativePath(root, target string) string {
+	rel, err := filepath.Rel(root, target)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return target
+	}
+	return rel
+}
+
+// matchSubtitle returns the subtitle of the language, the language without
+// region is matched when there is no exact match.
+func matchSubtitle(subtitles []models.SkillshareVideoSubtitle, lang string) (models.SkillshareVideoSubtitle, bool) {
+	for _, sub := range subtitles {
+		if strings.EqualFold(sub.Lang, lang) {
+			return sub, true
+		}
+	}
+
+	base := strings.ToLower(strings.Split(lang, "-")[0])
+	for _, sub := range subtitles {
+		if strings.ToLower(strings.Split(sub.Lang, "-")[0]) == base {
+			return sub, true
+		}
+	}
+
+	return models.SkillshareVideoSubtitle{}, false
+}
@@ -0,0 +1,8 @@
+package services
+
+import "github.com/rizalarfiyan/skillshare-downloader/models"
+
+type Search interface {
+	Update(conf models.Config) (*models.SearchStats, error)
+	Find(conf models.Config, phrase string, limit int) ([]models.SearchResult, error)
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rizalarfiyan/skillshare-downloader/logger"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/search"
+	"github.com/rizalarfiyan/skillshare-downloader/subtitle"
+	"github.com/rizalarfiyan/skillshare-downloader/utils"
+)
+
+type searchService struct {
+	conf models.AppConfig
+}
+
+func NewSearch() Search {
+	return &searchService{}
+}
+
+// Update index the new and changed subtitles of the download root, the
+// subtitles which are gone are removed from the index.
+func (s *searchService) Update(conf models.Config) (*models.SearchStats, error) {
+	logger.Debug("Load the config")
+	if err := s.conf.LoadLocal(conf); err != nil {
+		return nil, err
+	}
+
+	logger.Debugf("Search class directory: %s", s.conf.Dir)
+	dirs, err := findClassDirs(s.conf.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := search.Open(s.conf.Dir)
+	if err != nil {
+		return nil, err
+	}
+	defer index.Close()
+
+	stats := &models.SearchStats{}
+	seen := make(map[string]bool)
+	var skipped []string
+	for _, dir := range dirs {
+		lc, err := loadLocalClass(s.conf, dir.Path)
+		if err != nil {
+			logger.Warningf("Skip class directory %s: %s", dir.Path, err.Error())
+			skipped = append(skipped, relativePath(s.conf.Dir, dir.Path)+string(filepath.Separator))
+			continue
+		}
+
+		for _, doc := range lc.searchDocs(s.conf.Dir) {
+			seen[doc.Path] = true
+			stats.Total++
+			if index.IsFresh(doc.Path, doc.Size, doc.ModTime) {
+				continue
+			}
+
+			cues, err := subtitle.ParseVTTFile(filepath.Join(s.conf.Dir, doc.Path))
+			if err != nil {
+				logger.Warningf("[%d] Skip subtitle %s: %s", lc.class.ID, doc.Path, err.Error())
+				continue
+			}
+
+			for _, cue := range cues {
+				doc.Cues = append(doc.Cues, models.SearchCue{Start: cue.Start, End: cue.End, Text: cue.Text})
+			}
+
+			logger.Debugf("[%d] Index subtitle %s", lc.class.ID, doc.Path)
+			if err := index.Put(doc); err != nil {
+				return stats, err
+			}
+			stats.Indexed++
+		}
+	}
+
+	paths, err := index.Paths()
+	if err != nil {
+		return stats, err
+	}
+
+	for _, path := range paths {
+		// the entries of a class which failed to load are kept
+		if seen[path] || hasAnyPrefix(path, skipped) {
+			continue
+		}
+
+		logger.Debugf("Remove subtitle %s from the index", path)
+		if err := index.Delete(path); err != nil {
+			return stats, err
+		}
+		stats.Removed++
+	}
+
+	return stats, nil
+}
+
+func hasAnyPrefix(str string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(str, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Find returns the lessons where the phrase is said, the paths are joined
+// with the download root.
+func (s *searchService) Find(conf models.Config, phrase string, limit int) ([]models.SearchResult, error) {
+	if err := s.conf.LoadLocal(conf); err != nil {
+		return nil, err
+	}
+
+	index, err := search.Open(s.conf.Dir)
+	if err != nil {
+		return nil, err
+	}
+	defer index.Close()
+
+	results, err := index.Find(phrase, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for idx := range results {
+		results[idx].Path = filepath.Join(s.conf.Dir, results[idx].Path)
+		if results[idx].Video != "" {
+			results[idx].Video = filepath.Join(s.conf.Dir, results[idx].Video)
+		}
+	}
+	return results, nil
+}
+
+// searchDocs returns the downloaded subtitles of the class without the
+// cues, the paths are relative to root.
+func (lc *localClass) searchDocs(root string) []models.SearchDoc {
+	var docs []models.SearchDoc
+	seen := make(map[string]bool)
+	for idx, video := range lc.class.Videos {
+		var videoPath string
+		if source, ok := lc.layout.source(video); ok {
+			filePath, err := lc.layout.videoPath(lc.class, idx, source)
+			if err == nil && utils.IsExistPath(filePath) {
+				videoPath = relativePath(root, filePath)
+			}
+		}
+
+		for _, sub := range lc.preferLanguage(video.Subtitles) {
+			filePath, err := lc.layout.subtitlePath(lc.class, idx, sub)
+			if err != nil || seen[filePath] {
+				continue
+			}
+			seen[filePath] = true
+
+			info, err := os.Stat(filePath)
+			if err != nil {
+				continue
+			}
+
+			docs = append(docs, models.SearchDoc{
+				Path:       relativePath(root, filePath),
+				Video:      videoPath,
+				ClassID:    lc.class.ID,
+				ClassTitle: lc.class.Title,
+				Index:      idx + 1,
+				Lesson:     video.Title,
+				Lang:       sub.Lang,
+				Size:       info.Size(),
+				ModTime:    info.ModTime(),
+			})
+		}
+	}
+	return docs
+}
@@ -4,4 +4,5 @@ import "github.com/rizalarfiyan/skillshare-downloader/models"
 
 type Skillshare interface {
 	Run(conf models.Config) error
+	Sync(conf models.Config) (*models.SyncLog, error)
 }
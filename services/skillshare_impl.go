@@ -5,52 +5,80 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"log"
-	"net/http"
 	"os"
 	"path"
 	"path/filepath"
-	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/briandowns/spinner"
-	"github.com/cheggaaa/pb/v3"
-	"github.com/melbahja/got"
+	"github.com/rizalarfiyan/skillshare-downloader/client"
 	"github.com/rizalarfiyan/skillshare-downloader/constants"
 	"github.com/rizalarfiyan/skillshare-downloader/logger"
 	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/mp4"
+	"github.com/rizalarfiyan/skillshare-downloader/queue"
+	"github.com/rizalarfiyan/skillshare-downloader/reporter"
 	"github.com/rizalarfiyan/skillshare-downloader/utils"
 )
 
 type skillshare struct {
-	ctx  context.Context
-	conf models.AppConfig
-	spin *spinner.Spinner
-	dir  struct {
+	ctx      context.Context
+	conf     models.AppConfig
+	client   *client.Client
+	queue    *queue.Queue
+	only     map[int]bool
+	langs    map[string]bool
+	reporter reporter.Reporter
+	selector Selector
+	files    models.ClassFiles
+	filesMu  sync.Mutex
+	paths    *pathCache
+
+	dir struct {
 		base  string
 		json  string
 		video string
 	}
 }
 
-func NewSkillshare(ctx context.Context) Skillshare {
-	spin := spinner.New(spinner.CharSets[78], 100*time.Millisecond, func(s *spinner.Spinner) {
-		s.Color("green")
-	})
+type SkillshareOption func(*skillshare)
+
+// WithReporter is called on every event of the download, the events are
+// ignored by default.
+func WithReporter(r reporter.Reporter) SkillshareOption {
+	return func(s *skillshare) {
+		s.reporter = r
+	}
+}
+
+// Selector is called after the lesson data is loaded to pick what is
+// downloaded, a nil selection cancel the download.
+type Selector func(ss models.SkillshareClass) (*models.Selection, error)
+
+// WithSelector let the user pick the lessons, quality and languages.
+func WithSelector(fn Selector) SkillshareOption {
+	return func(s *skillshare) {
+		s.selector = fn
+	}
+}
 
-	return &skillshare{
-		ctx:  ctx,
-		spin: spin,
+func NewSkillshare(ctx context.Context, opts ...SkillshareOption) Skillshare {
+	ss := &skillshare{
+		ctx:      ctx,
+		reporter: reporter.NewQuiet(),
+		paths:    newPathCache(),
 	}
+	for _, opt := range opts {
+		opt(ss)
+	}
+	return ss
 }
 
-func (s *skillshare) Run(conf models.Config) error {
-	s.splash()
+func (s *skillshare) Run(conf models.Config) (err error) {
 	logger.Debug("Load the config")
-	if err := s.conf.FromConfig(conf); err != nil {
+	if err := s.loadConfig(conf); err != nil {
 		return err
 	}
 
@@ -60,6 +88,11 @@ func (s *skillshare) Run(conf models.Config) error {
 		return err
 	}
 
+	s.startQueue()
+	defer func() {
+		s.finishQueue(err)
+	}()
+
 	logger.Info("Success create directory")
 	logger.Debug("Load class data")
 	ssClass, err := s.loadClassData()
@@ -73,6 +106,10 @@ func (s *skillshare) Run(conf models.Config) error {
 		return err
 	}
 
+	if err := s.selectLessons(*ssData); err != nil {
+		return err
+	}
+
 	err = s.workerDownloadVideo(*ssData)
 	if err != nil {
 		return err
@@ -83,11 +120,38 @@ func (s *skillshare) Run(conf models.Config) error {
 		return err
 	}
 
+	logger.Debug("Create transcript")
+	err = s.createTranscript(*ssData)
+	if err != nil {
+		logger.Warningf("Failed create transcript: %s", err.Error())
+	}
+
+	logger.Debug("Create dual subtitles")
+	err = s.createDualSubtitles(*ssData)
+	if err != nil {
+		logger.Warningf("Failed create dual subtitles: %s", err.Error())
+	}
+
+	logger.Debug("Update library index")
+	err = indexClass(s.conf, s.dir.base)
+	if err != nil {
+		logger.Warningf("Failed update library index: %s", err.Error())
+	}
+
 	return nil
 }
 
-func (s *skillshare) splash() {
-	fmt.Printf("\n%s\n\n", constants.SplashScreen)
+func (s *skillshare) loadConfig(conf models.Config) error {
+	if err := s.conf.FromConfig(conf); err != nil {
+		return err
+	}
+
+	s.client = client.New(
+		client.WithCookies(s.conf.Cookies),
+		client.WithRateLimit(s.conf.RateLimit),
+	)
+	s.queue = queue.New(s.conf.Dir)
+	return nil
 }
 
 func (s *skillshare) initDir() error {
@@ -108,12 +172,12 @@ func (s *skillshare) initDir() error {
 		return strings.HasPrefix(dir, fmt.Sprintf("[%d]", s.conf.ID))
 	})
 
-	if len(dirs) != 1 {
+	if len(dirs) == 0 {
 		logger.Debug("Skip cache directory")
 		return nil
 	}
 
-	s.dir.base = path.Join(s.conf.Dir, dirs[0])
+	s.dir.base = s.pickClassDir(dirs)
 	logger.Debugf("Directory found: %s", s.dir.base)
 	err = s.loadDir()
 	if err != nil {
@@ -122,6 +186,32 @@ func (s *skillshare) initDir() error {
 	return nil
 }
 
+// pickClassDir returns the directory with the latest class data when the
+// class has more than one directory, like the folder of an older name.
+func (s *skillshare) pickClassDir(dirs []string) string {
+	if len(dirs) == 1 {
+		return path.Join(s.conf.Dir, dirs[0])
+	}
+
+	picked, latest := "", time.Time{}
+	for _, dir := range dirs {
+		info, err := os.Stat(path.Join(s.conf.Dir, dir, "json", constants.FilenameClassData))
+		if err != nil {
+			continue
+		}
+		if picked == "" || info.ModTime().After(latest) {
+			picked, latest = dir, info.ModTime()
+		}
+	}
+
+	if picked == "" {
+		picked = dirs[0]
+	}
+
+	logger.Warningf("Class %d has %d directories, use %s", s.conf.ID, len(dirs), picked)
+	return path.Join(s.conf.Dir, picked)
+}
+
 func (s *skillshare) loadDir() error {
 	if s.dir.base == "" {
 		logger.Debug("Skip load directory")
@@ -142,200 +232,367 @@ func (s *skillshare) loadDir() error {
 		return err
 	}
 
+	files, err := readClassFiles(s.dir.json)
+	if err != nil {
+		return err
+	}
+	if files != nil {
+		s.files = *files
+	}
+
 	return nil
 }
 
-func (s *skillshare) fetchClassApi() (*models.ClassData, error) {
-	client := &http.Client{}
-	url := fmt.Sprintf(constants.APIClass, s.conf.ID)
-	logger.Debugf("Prepare request API with class id: %d", s.conf.ID)
-	req, err := http.NewRequestWithContext(s.ctx, "GET", url, nil)
-	if err != nil {
-		return nil, err
+// recordFile keep the path written for the lesson, the commands working with
+// the downloaded classes read it back instead of rendering the templates.
+func (s *skillshare) recordFile(videoID int, fn func(lesson *models.LessonFiles)) {
+	s.filesMu.Lock()
+	defer s.filesMu.Unlock()
+
+	s.files.Update(videoID, fn)
+	if err := writeClassFiles(s.dir.json, &s.files); err != nil {
+		logger.Warningf("[%d] Failed record the lesson files: %s", videoID, err.Error())
 	}
+}
+
+// classFile returns the path relative to the class directory.
+func (s *skillshare) classFile(filePath string) string {
+	return filepath.ToSlash(relativePath(s.dir.base, filePath))
+}
+
+func (s *skillshare) report(event models.Event) {
+	event.ClassID = s.conf.ID
+	event.Time = time.Now()
+	s.reporter.Report(event)
+}
 
-	logger.Debugf("[%d] Prepare request header", s.conf.ID)
-	req.Header = http.Header{
-		"Accept":     {"application/vnd.skillshare.class+json;,version=0.8"},
-		"User-Agent": {"Skillshare/5.3.0; Android 9.0.1"},
-		"Host":       {"api.skillshare.com"},
-		"Referer":    {"https://www.skillshare.com/"},
-		"cookie":     {s.conf.Cookies},
+func (s *skillshare) reportProgress(progress models.Progress) {
+	s.report(models.Event{
+		Type:     models.EventProgress,
+		Stage:    models.StageVideo,
+		Current:  progress.Index,
+		Total:    progress.Total,
+		Lesson:   progress.Lesson,
+		Progress: &progress,
+	})
+}
+
+func (s *skillshare) reportError(stage string, lesson string, err error) {
+	s.report(models.Event{
+		Type:   models.EventError,
+		Stage:  stage,
+		Lesson: lesson,
+		Error:  err.Error(),
+	})
+}
+
+func (s *skillshare) selectLessons(ss models.SkillshareClass) error {
+	if s.selector == nil {
+		return nil
 	}
 
-	logger.Debugf("[%d] Send request to API", s.conf.ID)
-	resp, err := client.Do(req)
+	selection, err := s.selector(ss)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	logger.Debugf("[%d] Has status code: %d", s.conf.ID, resp.StatusCode)
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("Skillshare class not found")
+	if selection == nil {
+		return ErrCancelled
 	}
 
-	if resp.StatusCode == http.StatusInternalServerError {
-		return nil, fmt.Errorf("invalid Skillshare cookies")
+	s.only = make(map[int]bool)
+	for _, id := range selection.Lessons {
+		s.only[id] = true
 	}
 
-	logger.Debugf("[%d] Read response body", s.conf.ID)
-	dest := &models.ClassData{}
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+	s.conf.Quality = selection.Quality
+	if selection.Languages == nil {
+		return nil
+	}
+
+	s.langs = make(map[string]bool)
+	for _, lang := range selection.Languages {
+		s.langs[strings.ToLower(lang)] = true
+	}
+
+	if len(s.langs) > 1 {
+		return s.conf.MultiLangTemplate()
 	}
+	return nil
+}
+
+// isSelected returns false for the lessons skipped by sync.
+func (s *skillshare) isSelected(videoID int) bool {
+	return s.only == nil || s.only[videoID]
+}
+
+func (s *skillshare) layout() classLayout {
+	layout := newClassLayout(s.conf, s.dir.base)
+	layout.paths = s.paths
+	return layout
+}
 
-	logger.Debugf("[%d] Parse json response body to struct", s.conf.ID)
-	err = json.Unmarshal(body, dest)
+func (s *skillshare) fetchClassApi() (*models.ClassData, error) {
+	logger.Debugf("[%d] Send request to class API", s.conf.ID)
+	classData, err := s.client.GetClass(s.ctx, s.conf.ID)
+	if errors.Is(err, client.ErrNotFound) {
+		return nil, fmt.Errorf("Skillshare class %d not found", s.conf.ID)
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	logger.Debugf("[%d] Success get class data from api", s.conf.ID)
-	return dest, nil
+	return classData, nil
 }
 
 func (s *skillshare) fetchVideoApi(videoID int) (*models.VideoData, error) {
-	logger.Debugf("[%d] Prepare request API with video id", videoID)
-	client := &http.Client{}
-	url := fmt.Sprintf(constants.APIVideo, constants.BrightcoveAccountId, videoID)
-	req, err := http.NewRequestWithContext(s.ctx, "GET", url, nil)
+	logger.Debugf("[%d] Send request to video API", videoID)
+	video, err := s.client.GetPlayback(s.ctx, videoID)
+	if errors.Is(err, client.ErrNotFound) {
+		return nil, fmt.Errorf("Skillshare video id %d not found", videoID)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	logger.Debugf("[%d] Prepare request header", videoID)
-	req.Header = http.Header{
-		"Accept":     {fmt.Sprintf("application/json;pk=%s", constants.PolicyKey)},
-		"User-Agent": {"Mozilla/5.0 (X11; Linux x86_64; rv:52.0) Gecko/20100101 Firefox/52.0"},
-		"Origin":     {"https://www.skillshare.com/"},
+	logger.Debugf("[%d] Success get video data from api", videoID)
+	return video, nil
+}
+
+func (s *skillshare) fetchSubtitle(sub models.SubtitleWorker) ([]byte, error) {
+	logger.Debugf("[%d](%s) Send request to subtitle", sub.VideoId, sub.Label)
+	return s.client.GetSubtitle(s.ctx, sub.SkillshareVideoSubtitle)
+}
+
+func (s *skillshare) createJsonClass(classData models.ClassData) error {
+	logger.Debug("Pretty json class data")
+	value, err := json.MarshalIndent(classData, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	fileJson := path.Join(s.dir.json, constants.FilenameClassData)
+	logger.Debugf("Write json class data to file: %s", fileJson)
+	err = os.WriteFile(fileJson, value, 0o644)
+	if err != nil {
+		return err
 	}
 
-	logger.Debugf("[%d] Send request to API", videoID)
-	resp, err := client.Do(req)
+	logger.Debug("Do create json for cache meta")
+	err = s.createJsonClassMeta(classData.ID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	logger.Debugf("[%d] Has status code: %d", videoID, resp.StatusCode)
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("Skillshare video id %d not found", videoID)
+	logger.Debugf("Succes create json class id: %d", classData.ID)
+	return nil
+}
+
+func (s *skillshare) createJsonClassMeta(classId int) error {
+	value, err := json.MarshalIndent(models.CacheMeta{
+		ClassID:   classId,
+		FetchedAt: time.Now(),
+		Version:   constants.AppVersion,
+	}, "", "    ")
+	if err != nil {
+		return err
 	}
 
-	if resp.StatusCode == http.StatusInternalServerError {
-		return nil, fmt.Errorf("Skillshare video id %d internal server error", videoID)
+	fileJson := path.Join(s.dir.json, constants.FilenameClassMeta)
+	logger.Debugf("Write json cache meta to file: %s", fileJson)
+	return os.WriteFile(fileJson, value, 0o644)
+}
+
+// loadClassMeta fallback to the modified time of the class data for cache
+// created before the meta file exists.
+func (s *skillshare) loadClassMeta() (*models.CacheMeta, error) {
+	fileMeta := path.Join(s.dir.json, constants.FilenameClassMeta)
+	if !utils.IsExistPath(fileMeta) {
+		logger.Debug("No cache meta, use modified time of class data")
+		info, err := os.Stat(path.Join(s.dir.json, constants.FilenameClassData))
+		if err != nil {
+			return nil, err
+		}
+
+		return &models.CacheMeta{
+			ClassID:   s.conf.ID,
+			FetchedAt: info.ModTime(),
+		}, nil
 	}
 
-	dest := &models.VideoData{}
-	logger.Debugf("[%d] Read response body", videoID)
-	body, err := io.ReadAll(resp.Body)
+	data, err := os.ReadFile(fileMeta)
 	if err != nil {
 		return nil, err
 	}
 
-	logger.Debugf("[%d] Parse json response body to struct", videoID)
-	err = json.Unmarshal(body, dest)
+	dest := &models.CacheMeta{}
+	err = json.Unmarshal(data, dest)
 	if err != nil {
 		return nil, err
 	}
 
-	logger.Debugf("[%d] Success get video data from api", videoID)
 	return dest, nil
 }
 
-func (s *skillshare) fetchSubtitle(sub models.SubtitleWorker) ([]byte, error) {
-	client := &http.Client{}
-	logger.Debugf("Prepare request subtitle video id: %d (%s)", sub.VideoId, sub.Label)
-	req, err := http.NewRequestWithContext(s.ctx, "GET", sub.Src, nil)
+func (s *skillshare) createJsonVideo(idx int, videoData models.SkillshareVideo, sourceData models.VideoData) error {
+	logger.Debugf("[%d] Pretty json class data", videoData.ID)
+	value, err := json.MarshalIndent(sourceData, "", "    ")
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	logger.Debugf("[%d](%s) Prepare request header", sub.VideoId, sub.Label)
-	req.Header = http.Header{
-		"Accept":     {"application/vnd.skillshare.class+json;,version=0.8"},
-		"User-Agent": {"Skillshare/5.3.0; Android 9.0.1"},
-		"Host":       {"skillshare.com"},
-		"Referer":    {"https://www.skillshare.com/"},
+	fileJson := s.layout().videoDataPath(idx, videoData)
+	logger.Debugf("[%d] Write json class data to file: %s", videoData.ID, fileJson)
+	err = os.WriteFile(fileJson, value, 0o644)
+	if err != nil {
+		return err
 	}
 
-	logger.Debugf("[%d](%s) Send request to API", sub.VideoId, sub.Label)
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
+	s.recordFile(videoData.ID, func(lesson *models.LessonFiles) {
+		lesson.Data = s.classFile(fileJson)
+	})
+
+	logger.Debugf("[%d] Succes create json video id", videoData.ID)
+	return nil
+}
+
+func (s *skillshare) loadVideoDataCache(val models.VideoWorker) (*models.VideoData, error) {
+	if s.conf.IsRefresh {
+		return nil, nil
 	}
 
-	logger.Debugf("[%d](%s) Has status code: %d", sub.VideoId, sub.Label, resp.StatusCode)
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("subtitle not found")
+	fileJson := s.layout().videoDataPath(val.Idx, val.OriginalVideo)
+	if !utils.IsExistPath(fileJson) {
+		logger.Debugf("[%d] No video data cache", val.VideoId)
+		return nil, nil
 	}
 
-	if resp.StatusCode == http.StatusInternalServerError {
+	logger.Debugf("[%d] Load video data cache: %s", val.VideoId, fileJson)
+	data, err := os.ReadFile(fileJson)
+	if err != nil {
 		return nil, err
 	}
 
-	logger.Debugf("[%d](%s) Read response body", sub.VideoId, sub.Label)
-	body, err := io.ReadAll(resp.Body)
+	dest := &models.VideoData{}
+	err = json.Unmarshal(data, dest)
 	if err != nil {
 		return nil, err
 	}
 
-	logger.Debugf("[%d](%s) Success get subtitle data from api", sub.VideoId, sub.Label)
-	return body, nil
+	if dest.ID != strconv.Itoa(val.VideoId) {
+		logger.Debugf("[%d] Video data cache belongs to other video", val.VideoId)
+		return nil, nil
+	}
+
+	if dest.IsExpired(constants.SignedURLMargin) {
+		if s.conf.IsOffline {
+			logger.Warningf("[%d] Video data cache is expired, can not refresh in offline mode", val.VideoId)
+			return dest, nil
+		}
+
+		logger.Infof("[%d] Video data cache is expired, fetch new api again", val.VideoId)
+		return nil, nil
+	}
+
+	return dest, nil
 }
 
-func (s *skillshare) createJsonClass(classData models.ClassData) error {
-	logger.Debug("Pretty json class data")
-	value, err := json.MarshalIndent(classData, "", "    ")
+func (s *skillshare) loadVideoData(val models.VideoWorker) (*models.VideoData, error) {
+	cache, err := s.loadVideoDataCache(val)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	fileJson := path.Join(s.dir.json, constants.FilenameClassData)
-	logger.Debugf("Write json class data to file: %s", fileJson)
-	err = os.WriteFile(fileJson, value, os.ModePerm)
-	if err != nil {
-		log.Fatal(err)
+	if cache != nil {
+		logger.Debugf("[%d] Load video data from cache", val.VideoId)
+		s.recordFile(val.VideoId, func(lesson *models.LessonFiles) {
+			lesson.Data = s.classFile(s.layout().videoDataPath(val.Idx, val.OriginalVideo))
+		})
+		return cache, nil
 	}
 
-	logger.Debugf("Succes create json class id: %d", classData.ID)
-	return nil
-}
+	if s.conf.IsOffline {
+		return nil, fmt.Errorf("no cached video data for video id %d, run without offline", val.VideoId)
+	}
 
-func (s *skillshare) createJsonVideo(idx int, videoData models.SkillshareVideo, sourceData models.VideoData) error {
-	logger.Debugf("[%d] Pretty json class data", videoData.ID)
-	value, err := json.MarshalIndent(sourceData, "", "    ")
+	video, err := s.fetchVideoApi(val.VideoId)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	filename := fmt.Sprintf(constants.FilenameVideoData, idx+1, utils.ToSnakeCase(videoData.Title))
-	fileJson := path.Join(s.dir.json, filename)
-	logger.Debugf("[%d] Write json class data to file: %s", videoData.ID, fileJson)
-	err = os.WriteFile(fileJson, value, os.ModePerm)
+	logger.Debugf("[%d] Do create json", val.VideoId)
+	err = s.createJsonVideo(val.Idx, val.OriginalVideo, *video)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	logger.Debugf("[%d] Succes create json video id", videoData.ID)
-	return nil
+	return video, nil
+}
+
+// refreshSource fetch the video data again and returns the new url of the
+// source with the same height.
+func (s *skillshare) refreshSource(idx int, val models.SkillshareVideo, height int) func() (string, error) {
+	return func() (string, error) {
+		if s.conf.IsOffline {
+			return "", fmt.Errorf("[%d] source url is expired, can not refresh in offline mode", val.ID)
+		}
+
+		logger.Infof("[%d] Source url is expired, refresh the video data", val.ID)
+		video, err := s.fetchVideoApi(val.ID)
+		if err != nil {
+			return "", err
+		}
+
+		err = s.createJsonVideo(idx, val, *video)
+		if err != nil {
+			return "", err
+		}
+
+		fresh := val
+		fresh.AddSourceSubtitle(*video)
+		for _, source := range fresh.Sources {
+			if source.Height == height {
+				return source.Src, nil
+			}
+		}
+
+		source, ok := s.layout().source(fresh)
+		if !ok {
+			return "", fmt.Errorf("[%d] video has no source after refresh", val.ID)
+		}
+		return source.Src, nil
+	}
 }
 
 func (s *skillshare) createSubtitle(sub models.SubtitleWorker, data []byte) error {
-	extension := utils.MatchExtenstion(sub.Src, ".vtt")
-	filename := fmt.Sprintf(constants.FilenameSubtitle, sub.Idx+1, utils.ToSnakeCase(sub.Title), extension)
-	fileSubtitle := path.Join(s.dir.video, filename)
+	fileSubtitle := sub.Path
+	logger.Debugf("[%d](%s) Create directory: %s", sub.VideoId, sub.Label, path.Dir(fileSubtitle))
+	err := utils.CreateDir(path.Dir(fileSubtitle))
+	if err != nil {
+		return err
+	}
+
 	logger.Debugf("[%d](%s) Write json class data to file: %s", sub.VideoId, sub.Label, fileSubtitle)
-	err := os.WriteFile(fileSubtitle, data, os.ModePerm)
+	err = os.WriteFile(fileSubtitle, data, 0o644)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
+	s.recordFile(sub.VideoId, func(lesson *models.LessonFiles) {
+		lesson.SetSubtitle(sub.Lang, s.classFile(fileSubtitle))
+	})
+
 	logger.Debugf("[%d](%s) Succes create subtitle", sub.VideoId, sub.Label)
 	return nil
 }
 
 func (s *skillshare) loadClassDataCache() (*models.ClassData, error) {
+	if s.conf.IsRefresh {
+		logger.Info("Refresh class data, skip cache")
+		return nil, nil
+	}
+
 	if s.dir.base == "" || s.dir.json == "" {
 		logger.Info("No cache in directory")
 		return nil, nil
@@ -348,6 +605,21 @@ func (s *skillshare) loadClassDataCache() (*models.ClassData, error) {
 		return nil, nil
 	}
 
+	if !s.conf.IsOffline && s.conf.CacheTTL > 0 {
+		logger.Debug("Check cache freshness")
+		meta, err := s.loadClassMeta()
+		if err != nil {
+			return nil, err
+		}
+
+		age := time.Since(meta.FetchedAt)
+		if age > s.conf.CacheTTL {
+			logger.Infof("Cache is older than %s, fetch new api again", s.conf.CacheTTL)
+			return nil, nil
+		}
+		logger.Debugf("Cache fetched %s ago by version %s", age.Round(time.Second), meta.Version)
+	}
+
 	logger.Debugf("Load cache from directory: %s", fileJson)
 	data, err := os.ReadFile(fileJson)
 	if err != nil {
@@ -363,6 +635,9 @@ func (s *skillshare) loadClassDataCache() (*models.ClassData, error) {
 
 	logger.Debug("Check valid video id")
 	if !dest.IsValidVideoId() {
+		if s.conf.IsOffline {
+			return nil, errors.New("invalid video id in cache, can not fetch new api in offline mode")
+		}
 		logger.Warning("Invalid video id, fetch new api again")
 		return nil, nil
 	}
@@ -380,28 +655,36 @@ func (s *skillshare) loadClassData() (*models.ClassData, error) {
 
 	if getCache != nil {
 		logger.Info("Load class data from cache")
+		s.report(models.Event{
+			Type:    models.EventDone,
+			Stage:   models.StageClass,
+			Total:   len(getCache.Embedded.Sessions.Embedded.Sessions),
+			Message: getCache.Title,
+		})
 		return getCache, nil
 	}
 
-	if !s.conf.IsVerbose {
-		s.spin.Suffix = fmt.Sprintf(" Fetching skillshare class data with id %d\n", s.conf.ID)
-		s.spin.Start()
+	if s.conf.IsOffline {
+		return nil, fmt.Errorf("no cached class data for class id %d in %s, run without offline", s.conf.ID, s.conf.Dir)
 	}
 
+	s.report(models.Event{Type: models.EventStart, Stage: models.StageClass})
 	logger.Debug("Do load fetch data to api")
 	getData, err := s.fetchClassApi()
 	if err != nil {
+		s.reportError(models.StageClass, "", err)
 		return nil, err
 	}
 
-	if !s.conf.IsVerbose {
-		s.spin.Suffix = " Create skillshare json data\n"
+	// the directory found by the class id is kept, the folder name of an
+	// older version is not the same as the name of the title now
+	if s.dir.base == "" {
+		safeTitle := utils.SafeNameOr(getData.Title, strconv.Itoa(getData.ID))
+		folderName := fmt.Sprintf(constants.FolderName, s.conf.ID, safeTitle)
+		logger.Debugf("Prepare folder name: %s", folderName)
+		s.dir.base = path.Join(s.conf.Dir, folderName)
 	}
 
-	safeTitle := utils.SafeName(getData.Title)
-	folderName := fmt.Sprintf(constants.FolderName, s.conf.ID, safeTitle)
-	logger.Debugf("Prepare folder name: %s", folderName)
-	s.dir.base = path.Join(s.conf.Dir, folderName)
 	logger.Debugf("Create directory: %s", s.dir.base)
 	err = s.loadDir()
 	if err != nil {
@@ -414,10 +697,12 @@ func (s *skillshare) loadClassData() (*models.ClassData, error) {
 		return nil, err
 	}
 
-	if !s.conf.IsVerbose {
-		s.spin.Suffix = " Fetching skillshare done\n"
-		s.spin.Stop()
-	}
+	s.report(models.Event{
+		Type:    models.EventDone,
+		Stage:   models.StageClass,
+		Total:   len(getData.Embedded.Sessions.Embedded.Sessions),
+		Message: getData.Title,
+	})
 
 	logger.Info("Skillshare class data is ready")
 	logger.Debug("Check valid video id")
@@ -459,15 +744,7 @@ func (s *skillshare) actionWorkerVideo(chanIn <-chan models.VideoWorker) <-chan
 			go func(workerIdx int) {
 				for val := range chanIn {
 					logger.Debugf("[%d] Do run video: %s", val.VideoId, val.Name)
-					video, err := s.fetchVideoApi(val.VideoId)
-					if err != nil {
-						val.Error = err
-						chanWorker <- val
-						continue
-					}
-
-					logger.Debugf("[%d] Do create json", val.VideoId)
-					err = s.createJsonVideo(val.Idx, val.OriginalVideo, *video)
+					video, err := s.loadVideoData(val)
 					if err != nil {
 						val.Error = err
 						chanWorker <- val
@@ -495,36 +772,46 @@ func (s *skillshare) workerVideoData(ssClass models.ClassData) (*models.Skillsha
 	logger.Debug("Mapping response api to new struct")
 	ss := ssClass.Mapper()
 
-	if !s.conf.IsVerbose {
-		s.spin.Suffix = fmt.Sprintf(" \x1b[36m[%d/%d]\x1b[0m Fetching skillshare video data with id\n", 0, len(ss.Videos))
-		s.spin.Start()
-	}
-
+	s.report(models.Event{Type: models.EventStart, Stage: models.StageMetadata, Total: len(ss.Videos)})
 	chanIn := s.createWorkerVideo(ss)
 	chanOut := s.actionWorkerVideo(chanIn)
 
+	var errRateLimited error
 	countError := 0
 	countSuccess := 0
 	for worker := range chanOut {
 		if worker.Error != nil {
 			logger.Warningf("Error get video %s", worker.Error.Error())
+			s.updateLesson(worker.Idx, worker.OriginalVideo, func(lesson *models.QueueLesson) {
+				lesson.Status = models.JobFailed
+				lesson.LastError = worker.Error.Error()
+			})
+			s.reportError(models.StageMetadata, worker.OriginalVideo.Title, worker.Error)
+			if errors.Is(worker.Error, ErrRateLimited) {
+				errRateLimited = worker.Error
+			}
 			countError++
 			continue
 		}
 
 		countSuccess++
-		if !s.conf.IsVerbose {
-			s.spin.Suffix = fmt.Sprintf(" \x1b[36m[%d/%d]\x1b[0m Fetching skillshare video data with id\n", countSuccess, len(ss.Videos))
-		}
+		s.report(models.Event{
+			Type:    models.EventStep,
+			Stage:   models.StageMetadata,
+			Current: countSuccess,
+			Total:   len(ss.Videos),
+			Lesson:  worker.OriginalVideo.Title,
+		})
 
 		logger.Debugf("[%d] Mapping data source to subtitle", worker.VideoId)
 		ss.Videos[worker.Idx].AddSourceSubtitle(*worker.Video)
 		logger.Debugf("[%d] Success fetch video: %03d. %s", worker.VideoId, worker.Idx+1, ss.Videos[worker.Idx].Title)
 	}
 
-	if !s.conf.IsVerbose {
-		s.spin.Suffix = " Fetching skillshare video done\n"
-		s.spin.Stop()
+	s.report(models.Event{Type: models.EventDone, Stage: models.StageMetadata, Current: countSuccess, Total: len(ss.Videos)})
+
+	if errRateLimited != nil {
+		return nil, errRateLimited
 	}
 
 	logger.Info("All video data is ready")
@@ -558,43 +845,149 @@ func (s *skillshare) workerDownloadVideo(ssData models.SkillshareClass) error {
 		}
 	}()
 
+	state, err := s.queue.Get(ssData.ID)
+	if err != nil {
+		logger.Debugf("No download queue state: %s", err.Error())
+	}
+
+	s.updateQueue(func(class *models.QueueClass) {
+		class.Title = ssData.Title
+		for idx, val := range ssData.Videos {
+			if s.isSelected(val.ID) {
+				class.Lesson(idx+1, val.ID, val.Title)
+			}
+		}
+	})
+
+	s.report(models.Event{Type: models.EventStart, Stage: models.StageVideo, Total: len(ssData.Videos)})
 	for idx, val := range ssData.Videos {
+		if !s.isSelected(val.ID) {
+			continue
+		}
+
 		title := utils.SafeName(val.Title)
-		if len(val.Sources) < 1 {
+		source, ok := s.layout().source(val)
+		if !ok {
 			logger.Warningf("[%d] Video %s has no source", val.ID, title)
 			logger.Infof("[%d] Skipping download", val.ID)
+			s.updateLesson(idx, val, func(lesson *models.QueueLesson) {
+				lesson.Status = models.JobFailed
+				lesson.LastError = "video has no source"
+			})
 			continue
 		}
 
-		logger.Debugf("[%d] Preapare download video", val.ID)
-		source := val.Sources[0]
+		logger.Debugf("[%d] Preapare download video %dp", val.ID, source.Height)
 
-		extension := utils.MatchExtenstion(source.Src, fmt.Sprintf(".%s", strings.ToLower(source.Container)))
-		fileName := fmt.Sprintf(constants.FilenameVideo, idx+1, utils.ToSnakeCase(title), extension)
-		filePath := filepath.Join(s.dir.video, fileName)
+		filePath, err := s.layout().videoPath(ssData, idx, source)
+		if err != nil {
+			return err
+		}
 
-		logger.Infof("\x1b[36m\x1b[36m[%d/%d]\x1b[0m\x1b[0m %s", idx+1, len(ssData.Videos), val.Title)
-		var bar *pb.ProgressBar
-		dl := got.New()
-		dl.ProgressFunc = func(download *got.Download) {
-			download.Concurrency = uint(runtime.NumCPU())
-			if bar == nil {
-				bar = pb.ProgressBarTemplate(constants.ProgressBarTemplate).Start64(int64(download.TotalSize()))
-				bar.Set(pb.Bytes, true)
-			}
-			bar.SetCurrent(int64(download.Size()))
+		logger.Debugf("[%d] Create directory: %s", val.ID, filepath.Dir(filePath))
+		err = utils.CreateDir(filepath.Dir(filePath))
+		if err != nil {
+			return err
+		}
+
+		recordVideo := func(lesson *models.LessonFiles) {
+			lesson.Video = s.classFile(filePath)
+			lesson.Height = source.Height
+			lesson.IsAudioOnly = s.conf.IsAudioOnly
+		}
+
+		if s.isDownloaded(state, val, filePath) {
+			s.recordFile(val.ID, recordVideo)
+			logger.Infof("[%d/%d] %s is already downloaded", idx+1, len(ssData.Videos), val.Title)
+			s.report(models.Event{
+				Type:    models.EventStep,
+				Stage:   models.StageVideo,
+				Current: idx + 1,
+				Total:   len(ssData.Videos),
+				Lesson:  val.Title,
+				Message: "already downloaded",
+			})
+			continue
+		}
+
+		s.updateLesson(idx, val, func(lesson *models.QueueLesson) {
+			lesson.Status = models.JobRunning
+			lesson.Attempts++
+			lesson.LastError = ""
+			lesson.BytesDone = 0
+		})
+
+		logger.Infof("[%d/%d] %s", idx+1, len(ssData.Videos), val.Title)
+		progress := models.Progress{
+			ClassID:    ssData.ID,
+			ClassTitle: ssData.Title,
+			Index:      idx + 1,
+			Total:      len(ssData.Videos),
+			Lesson:     val.Title,
+		}
+		s.reportProgress(progress)
+
+		lastSave := time.Now()
+		opts := client.DownloadOptions{
+			Quality:   s.conf.Quality,
+			Refresh:   s.refreshSource(idx, val, source.Height),
+			AudioOnly: s.conf.IsAudioOnly,
+			Tags: mp4.Tags{
+				Title:      val.Title,
+				Album:      ssData.Title,
+				Artist:     ssData.Teacher,
+				Track:      idx + 1,
+				TrackTotal: len(ssData.Videos),
+			},
+			Progress: func(p client.Progress) {
+				progress.Bytes = p.Bytes
+				progress.TotalBytes = p.TotalBytes
+				progress.Speed = p.Speed
+				s.reportProgress(progress)
+
+				if time.Since(lastSave) >= constants.QueueSaveInterval {
+					lastSave = time.Now()
+					s.updateLesson(idx, val, func(lesson *models.QueueLesson) {
+						lesson.BytesDone = progress.Bytes
+						lesson.TotalBytes = progress.TotalBytes
+					})
+				}
+			},
 		}
 
 		logger.Debugf("[%d] Do download video: %s", val.ID, val.Title)
-		err := dl.Download(source.Src, filePath)
+		result, err := s.client.DownloadLesson(s.ctx, val, filePath, opts)
 		if err != nil {
+			s.updateLesson(idx, val, func(lesson *models.QueueLesson) {
+				lesson.Status = models.JobFailed
+				lesson.LastError = err.Error()
+				lesson.BytesDone = progress.Bytes
+			})
+			s.reportError(models.StageVideo, val.Title, err)
 			return err
 		}
 
-		bar.SetCurrent(bar.Total())
-		bar.Finish()
-	}
-
+		s.recordFile(val.ID, recordVideo)
+		progress.TotalBytes = result.Size
+		s.updateLesson(idx, val, func(lesson *models.QueueLesson) {
+			lesson.Status = models.JobDone
+			lesson.BytesDone = progress.TotalBytes
+			lesson.TotalBytes = progress.TotalBytes
+		})
+
+		progress.Bytes = progress.TotalBytes
+		progress.Done = true
+		s.reportProgress(progress)
+		s.report(models.Event{
+			Type:    models.EventStep,
+			Stage:   models.StageVideo,
+			Current: idx + 1,
+			Total:   len(ssData.Videos),
+			Lesson:  val.Title,
+		})
+	}
+
+	s.report(models.Event{Type: models.EventDone, Stage: models.StageVideo, Total: len(ssData.Videos)})
 	logger.Info("Download video done")
 
 	return nil
@@ -603,21 +996,31 @@ func (s *skillshare) workerDownloadVideo(ssData models.SkillshareClass) error {
 func (s *skillshare) createWorkerSubtitle(ss models.SkillshareClass) <-chan models.SubtitleWorker {
 	chanWorker := make(chan models.SubtitleWorker)
 
-	lang := s.checkLanguage(ss)
-	s.conf.Lang = lang.Lang
+	if s.langs == nil {
+		lang := s.checkLanguage(ss)
+		s.conf.Lang = lang.Lang
+		s.langs = map[string]bool{strings.ToLower(lang.Lang): true}
+	}
 
 	go func() {
 		for idx, val := range ss.Videos {
+			if !s.isSelected(val.ID) {
+				continue
+			}
+
 			for _, sub := range val.Subtitles {
-				if !strings.EqualFold(lang.Lang, sub.Lang) {
+				if !s.langs[strings.ToLower(sub.Lang)] && !s.isDualSub(val, sub) {
 					continue
 				}
 
+				filePath, err := s.layout().subtitlePath(ss, idx, sub)
 				chanWorker <- models.SubtitleWorker{
 					SkillshareVideoSubtitle: sub,
 					Title:                   val.Title,
+					Path:                    filePath,
 					Idx:                     idx,
 					VideoId:                 val.ID,
+					Error:                   err,
 				}
 			}
 		}
@@ -638,6 +1041,11 @@ func (s *skillshare) actionWorkerSubtitle(chanIn <-chan models.SubtitleWorker) <
 		for workerIdx := 0; workerIdx < s.conf.Worker; workerIdx++ {
 			go func(workerIdx int) {
 				for val := range chanIn {
+					if val.Error != nil {
+						chanWorker <- val
+						continue
+					}
+
 					logger.Debugf("[%d](%s) Do run download sutitle", val.VideoId, val.Label)
 					data, err := s.fetchSubtitle(val)
 					if err != nil {
@@ -715,11 +1123,7 @@ func (s *skillshare) checkLanguage(ssData models.SkillshareClass) models.Checkla
 }
 
 func (s *skillshare) workerDownloadSubtitle(ss models.SkillshareClass) error {
-	if !s.conf.IsVerbose {
-		s.spin.Suffix = fmt.Sprintf(" \x1b[36m[%d/%d]\x1b[0m Fetching skillshare video data with id\n", 0, len(ss.Videos))
-		s.spin.Start()
-	}
-
+	s.report(models.Event{Type: models.EventStart, Stage: models.StageSubtitle, Total: len(ss.Videos)})
 	chanIn := s.createWorkerSubtitle(ss)
 	chanOut := s.actionWorkerSubtitle(chanIn)
 
@@ -728,20 +1132,23 @@ func (s *skillshare) workerDownloadSubtitle(ss models.SkillshareClass) error {
 	for worker := range chanOut {
 		if worker.Error != nil {
 			logger.Warningf("Error get subtitle %s", worker.Error.Error())
+			s.reportError(models.StageSubtitle, worker.Title, worker.Error)
 			countError++
 			continue
 		}
 
 		countSuccess++
-		if !s.conf.IsVerbose {
-			s.spin.Suffix = fmt.Sprintf(" \x1b[36m[%d/%d]\x1b[0m Download skillshare subtitle data with language %s\n", countSuccess, len(ss.Videos), worker.Label)
-		}
+		s.report(models.Event{
+			Type:    models.EventStep,
+			Stage:   models.StageSubtitle,
+			Current: countSuccess,
+			Total:   len(ss.Videos),
+			Lesson:  worker.Title,
+			Message: fmt.Sprintf("with language %s", worker.Label),
+		})
 	}
 
-	if !s.conf.IsVerbose {
-		s.spin.Suffix = " Download skillshare subtitle done\n"
-		s.spin.Stop()
-	}
+	s.report(models.Event{Type: models.EventDone, Stage: models.StageSubtitle, Current: countSuccess, Total: len(ss.Videos)})
 
 	logger.Info("Download subtitle done")
 
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/rizalarfiyan/skillshare-downloader/logger"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+)
+
+// startQueue mark the class as running in the persisted queue, a failure to
+// write the queue only logs a warning and the download continue.
+func (s *skillshare) startQueue() {
+	s.updateQueue(func(class *models.QueueClass) {
+		if class.Class == "" {
+			class.Class = strconv.Itoa(s.conf.ID)
+		}
+		class.Status = models.JobRunning
+		class.Attempts++
+		class.LastError = ""
+	})
+}
+
+// finishQueue mark the class cancelled only when the user cancelled it, the
+// class interrupted by a shutdown keeps its status to be resumed later.
+func (s *skillshare) finishQueue(err error) {
+	s.updateQueue(func(class *models.QueueClass) {
+		unfinished := s.unfinishedLessons(class)
+		switch {
+		case err == nil && unfinished > 0:
+			// the failed lessons are downloaded again by the retry
+			class.Status = models.JobFailed
+			class.LastError = fmt.Sprintf("%d lessons are not downloaded", unfinished)
+		case err == nil:
+			class.Status = models.JobDone
+			class.LastError = ""
+		case errors.Is(err, ErrCancelled), errors.Is(context.Cause(s.ctx), ErrCancelled):
+			class.Status = models.JobCancelled
+			class.LastError = err.Error()
+		case s.ctx.Err() != nil:
+			class.LastError = err.Error()
+		default:
+			class.Status = models.JobFailed
+			class.LastError = err.Error()
+		}
+	})
+}
+
+// unfinishedLessons count the selected lessons which are not done, like the
+// lessons without metadata or without source.
+func (s *skillshare) unfinishedLessons(class *models.QueueClass) int {
+	count := 0
+	for _, lesson := range class.Lessons {
+		if s.isSelected(lesson.ID) && lesson.Status != models.JobDone {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *skillshare) updateQueue(fn func(class *models.QueueClass)) {
+	_, err := s.queue.Update(s.conf.ID, func(class *models.QueueClass) error {
+		fn(class)
+		return nil
+	})
+	if err != nil {
+		logger.Warningf("Failed update download queue: %s", err.Error())
+	}
+}
+
+func (s *skillshare) updateLesson(idx int, val models.SkillshareVideo, fn func(lesson *models.QueueLesson)) {
+	s.updateQueue(func(class *models.QueueClass) {
+		lesson := class.Lesson(idx+1, val.ID, val.Title)
+		fn(lesson)
+		lesson.UpdatedAt = time.Now()
+	})
+}
+
+// isDownloaded returns true when the queue has the lesson done and the file
+// still has the same size, refresh download it again.
+func (s *skillshare) isDownloaded(state *models.QueueClass, val models.SkillshareVideo, filePath string) bool {
+	if s.conf.IsRefresh || state == nil {
+		return false
+	}
+
+	for _, lesson := range state.Lessons {
+		if lesson.ID != val.ID || lesson.Status != models.JobDone {
+			continue
+		}
+
+		info, err := os.Stat(filePath)
+		return err == nil && info.Size() == lesson.TotalBytes
+	}
+
+	return false
+}
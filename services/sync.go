@@ -0,0 +1,474 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+	"github.com/rizalarfiyan/skillshare-downloader/logger"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/utils"
+)
+
+// Sync fetch the class data again and only download the lessons added or
+// updated since the cached class data, the replaced files are moved to the
+// archive directory once the new video is downloaded.
+func (s *skillshare) Sync(conf models.Config) (_ *models.SyncLog, err error) {
+	logger.Debug("Load the config")
+	if err := s.loadConfig(conf); err != nil {
+		return nil, err
+	}
+
+	if s.conf.IsOffline {
+		return nil, errors.New("sync need to fetch class data, can not run in offline mode")
+	}
+
+	logger.Debug("Initial directory")
+	if err := s.initDir(); err != nil {
+		return nil, err
+	}
+
+	if s.dir.base == "" {
+		return nil, fmt.Errorf("%w: %d in %s", ErrNotDownloaded, s.conf.ID, s.conf.Dir)
+	}
+
+	s.startQueue()
+	defer func() {
+		s.finishQueue(err)
+	}()
+
+	logger.Debug("Load cached class")
+	cached, err := loadLocalClass(s.conf, s.dir.base)
+	if err != nil {
+		return nil, err
+	}
+
+	s.report(models.Event{Type: models.EventStart, Stage: models.StageClass})
+	logger.Debug("Do load fetch data to api")
+	classData, err := s.fetchClassApi()
+	if err != nil {
+		s.reportError(models.StageClass, "", err)
+		return nil, err
+	}
+
+	s.report(models.Event{
+		Type:    models.EventDone,
+		Stage:   models.StageClass,
+		Total:   len(classData.Embedded.Sessions.Embedded.Sessions),
+		Message: classData.Title,
+	})
+
+	if !classData.IsValidVideoId() {
+		return nil, errors.New("invalid video id, please use cookies with premium account")
+	}
+
+	fresh := classData.Mapper()
+	syncLog := &models.SyncLog{
+		ClassID:  fresh.ID,
+		Title:    fresh.Title,
+		SyncedAt: time.Now(),
+		Version:  constants.AppVersion,
+	}
+
+	logger.Debug("Compare class data with cache")
+	staged, err := s.syncFiles(cached, fresh, syncLog)
+	if err != nil {
+		return nil, err
+	}
+
+	// the old files of the updated lessons are put back when the sync fails
+	defer func() {
+		if err != nil {
+			s.restoreStaged(staged)
+		}
+	}()
+
+	logger.Debug("Do create json for data class")
+	if err := s.createJsonClass(*classData); err != nil {
+		return nil, err
+	}
+
+	s.only = syncLog.Downloads()
+	if len(s.only) > 0 {
+		logger.Infof("Download %d new or updated lessons", len(s.only))
+		ssData, err := s.workerVideoData(*classData)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.workerDownloadVideo(*ssData); err != nil {
+			return nil, err
+		}
+
+		if err := s.workerDownloadSubtitle(*ssData); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Debug("Archive the replaced files")
+	if err := s.finishStaged(staged, syncLog); err != nil {
+		return nil, err
+	}
+
+	logger.Debug("Write change log")
+	if err := s.appendChangeLog(*syncLog); err != nil {
+		return nil, err
+	}
+
+	logger.Debug("Update library index")
+	if err := indexClass(s.conf, s.dir.base); err != nil {
+		logger.Warningf("Failed update library index: %s", err.Error())
+	}
+
+	return syncLog, nil
+}
+
+type syncLesson struct {
+	idx   int
+	video models.SkillshareVideo
+}
+
+// syncStage are the files of an updated lesson renamed aside until the new
+// video is downloaded, the key is the original path.
+type syncStage struct {
+	videoID int
+	change  int
+	files   map[string]string
+	roles   map[string]string
+}
+
+// syncMove are the files of a lesson moved to a new position by role.
+type syncMove struct {
+	videoID     int
+	height      int
+	isAudioOnly bool
+	files       map[string]string
+}
+
+// syncFiles fill the changes of the lessons, archive the files of removed
+// lessons, rename aside the files of updated lessons and move the files of
+// lessons with a new position. The files are matched by role, a lesson with
+// a new subtitle language only moves the files it had.
+func (s *skillshare) syncFiles(cached *localClass, fresh models.SkillshareClass, syncLog *models.SyncLog) ([]syncStage, error) {
+	cachedLessons := make(map[int]syncLesson)
+	for idx, video := range cached.class.Videos {
+		cachedLessons[video.SessionID] = syncLesson{idx, video}
+	}
+
+	// sources of the kept lessons, to resolve the new path of cached files
+	current := fresh
+	current.Videos = make([]models.SkillshareVideo, len(fresh.Videos))
+	copy(current.Videos, fresh.Videos)
+
+	var archives []string
+	var archived []int
+	var staged []syncStage
+	var moved []syncMove
+	moves := make(map[string]string)
+	for idx, video := range fresh.Videos {
+		change := models.SyncChange{
+			SessionID: video.SessionID,
+			VideoID:   video.ID,
+			Title:     video.Title,
+			Index:     idx + 1,
+		}
+
+		old, ok := cachedLessons[video.SessionID]
+		if !ok {
+			change.Kind = models.SyncAdded
+			syncLog.Changes = append(syncLog.Changes, change)
+			continue
+		}
+
+		delete(cachedLessons, video.SessionID)
+		change.OldIndex = old.idx + 1
+		oldFiles, err := cached.layout.lessonFiles(cached.class, old.idx)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case old.video.VideoID != video.VideoID:
+			change.Kind = models.SyncUpdated
+			change.Reason = fmt.Sprintf("video replaced from %s", old.video.VideoID)
+		case old.video.UpdateTime != video.UpdateTime:
+			change.Kind = models.SyncUpdated
+			change.Reason = fmt.Sprintf("updated at %s", video.UpdateTime)
+		}
+
+		if change.Kind == models.SyncUpdated {
+			staged = append(staged, syncStage{
+				videoID: video.ID,
+				change:  len(syncLog.Changes),
+				roles:   oldFiles,
+			})
+			archived = append(archived, old.video.ID)
+			syncLog.Changes = append(syncLog.Changes, change)
+			continue
+		}
+
+		current.Videos[idx].Sources = old.video.Sources
+		current.Videos[idx].Subtitles = old.video.Subtitles
+		layout := s.syncLayout(cached, old.video)
+		newFiles, err := layout.lessonFiles(current, idx)
+		if err != nil {
+			return nil, err
+		}
+
+		lessonMove := syncMove{videoID: video.ID, isAudioOnly: layout.conf.IsAudioOnly, files: make(map[string]string)}
+		if source, ok := layout.source(current.Videos[idx]); ok {
+			lessonMove.height = source.Height
+		}
+		for role, oldPath := range oldFiles {
+			newPath, ok := newFiles[role]
+			if ok && oldPath != newPath && utils.IsExistPath(oldPath) {
+				moves[oldPath] = newPath
+				lessonMove.files[role] = newPath
+			}
+		}
+
+		if len(lessonMove.files) > 0 {
+			moved = append(moved, lessonMove)
+			change.Kind = models.SyncMoved
+			syncLog.Changes = append(syncLog.Changes, change)
+		}
+	}
+
+	for _, old := range cachedLessons {
+		syncLog.Changes = append(syncLog.Changes, models.SyncChange{
+			Kind:      models.SyncRemoved,
+			SessionID: old.video.SessionID,
+			VideoID:   old.video.ID,
+			Title:     old.video.Title,
+			OldIndex:  old.idx + 1,
+		})
+
+		oldFiles, err := cached.layout.lessonFiles(cached.class, old.idx)
+		if err != nil {
+			return nil, err
+		}
+		archives = append(archives, fileValues(oldFiles)...)
+		archived = append(archived, old.video.ID)
+	}
+
+	if err := s.archiveFiles(archives, syncLog); err != nil {
+		return nil, err
+	}
+
+	// out of the way of the moved files and of the new download
+	for idx := range staged {
+		if err := stageFiles(&staged[idx]); err != nil {
+			s.restoreStaged(staged)
+			return nil, err
+		}
+	}
+
+	if err := moveFiles(moves); err != nil {
+		s.restoreStaged(staged)
+		return nil, err
+	}
+
+	for _, videoID := range archived {
+		s.recordFile(videoID, func(lesson *models.LessonFiles) {
+			*lesson = models.LessonFiles{}
+		})
+	}
+
+	for _, lessonMove := range moved {
+		s.recordFile(lessonMove.videoID, func(lesson *models.LessonFiles) {
+			for role, filePath := range lessonMove.files {
+				setLessonFile(lesson, role, s.classFile(filePath))
+			}
+
+			if _, ok := lessonMove.files[roleVideo]; ok && lesson.Height == 0 {
+				lesson.Height = lessonMove.height
+				lesson.IsAudioOnly = lessonMove.isAudioOnly
+			}
+		})
+	}
+	return staged, nil
+}
+
+// syncLayout returns the layout of the current templates, a written video
+// keeps its source and only moves to the new path.
+func (s *skillshare) syncLayout(cached *localClass, video models.SkillshareVideo) classLayout {
+	layout := s.layout()
+	if lesson := cached.layout.files.Lesson(video.ID); lesson != nil && lesson.Video != "" {
+		layout.conf.Quality = lesson.Height
+		layout.conf.IsAudioOnly = lesson.IsAudioOnly
+	}
+	return layout
+}
+
+func fileValues(files map[string]string) []string {
+	values := make([]string, 0, len(files))
+	for _, filePath := range files {
+		values = append(values, filePath)
+	}
+	sort.Strings(values)
+	return values
+}
+
+// archiveFiles move the files to a new archive directory, the path inside
+// the class directory is kept.
+func (s *skillshare) archiveFiles(files []string, syncLog *models.SyncLog) error {
+	for _, file := range files {
+		if err := s.archiveFile(file, file, syncLog); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// archiveFile move the file to the archive under the path of the original.
+func (s *skillshare) archiveFile(file, original string, syncLog *models.SyncLog) error {
+	if !utils.IsExistPath(file) {
+		return nil
+	}
+
+	archive := path.Join(s.dir.base, constants.FolderArchive, syncLog.SyncedAt.Format(constants.ArchiveTimeFormat))
+	target := path.Join(archive, relativePath(s.dir.base, original))
+	logger.Debugf("Archive file %s to %s", file, target)
+	if err := utils.CreateDir(filepath.Dir(target)); err != nil {
+		return err
+	}
+
+	if err := os.Rename(file, target); err != nil {
+		return err
+	}
+	syncLog.Archive = relativePath(s.dir.base, archive)
+	return nil
+}
+
+// stageFiles rename the existing files of the updated lesson to a temporary
+// name next to them.
+func stageFiles(stage *syncStage) error {
+	stage.files = make(map[string]string)
+	for _, file := range fileValues(stage.roles) {
+		if !utils.IsExistPath(file) {
+			continue
+		}
+
+		temp := file + ".sync"
+		logger.Debugf("Stage file %s to %s", file, temp)
+		if err := os.Rename(file, temp); err != nil {
+			return err
+		}
+		stage.files[file] = temp
+	}
+	return nil
+}
+
+// finishStaged archive the old files of the lessons downloaded again, the
+// old files of a lesson which is not downloaded are put back.
+func (s *skillshare) finishStaged(staged []syncStage, syncLog *models.SyncLog) error {
+	state, err := s.queue.Get(s.conf.ID)
+	if err != nil {
+		logger.Debugf("No download queue state: %s", err.Error())
+	}
+
+	for _, stage := range staged {
+		if !isLessonDone(state, stage.videoID) {
+			logger.Warningf("[%d] Lesson is not downloaded again, keep the old files", stage.videoID)
+			syncLog.Changes[stage.change].Reason += ", not downloaded and the old files are kept"
+			s.restoreStaged([]syncStage{stage})
+			continue
+		}
+
+		for original, temp := range stage.files {
+			if err := s.archiveFile(temp, original, syncLog); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// restoreStaged put back the old files and their records, a file keeps its
+// temporary name when its path is taken by a moved lesson.
+func (s *skillshare) restoreStaged(staged []syncStage) {
+	for _, stage := range staged {
+		for role, original := range stage.roles {
+			temp, ok := stage.files[original]
+			if !ok {
+				continue
+			}
+
+			if utils.IsExistPath(original) {
+				logger.Warningf("[%d] Path of %s is taken, it stays in %s", stage.videoID, original, temp)
+				continue
+			}
+
+			if err := os.Rename(temp, original); err != nil {
+				logger.Warningf("[%d] Failed restore %s: %s", stage.videoID, original, err.Error())
+				continue
+			}
+
+			s.recordFile(stage.videoID, func(lesson *models.LessonFiles) {
+				setLessonFile(lesson, role, s.classFile(original))
+			})
+		}
+	}
+}
+
+func isLessonDone(state *models.QueueClass, videoID int) bool {
+	if state == nil {
+		return false
+	}
+
+	for _, lesson := range state.Lessons {
+		if lesson.ID == videoID {
+			return lesson.Status == models.JobDone
+		}
+	}
+	return false
+}
+
+// moveFiles rename through a temporary name first, so lessons swapping
+// position do not overwrite each other.
+func moveFiles(moves map[string]string) error {
+	temps := make(map[string]string)
+	for from, to := range moves {
+		temp := from + ".sync"
+		if err := os.Rename(from, temp); err != nil {
+			return err
+		}
+		temps[temp] = to
+	}
+
+	for temp, to := range temps {
+		logger.Debugf("Move file %s to %s", temp, to)
+		if err := utils.CreateDir(filepath.Dir(to)); err != nil {
+			return err
+		}
+
+		if err := os.Rename(temp, to); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *skillshare) appendChangeLog(syncLog models.SyncLog) error {
+	value, err := json.Marshal(syncLog)
+	if err != nil {
+		return err
+	}
+
+	fileLog := path.Join(s.dir.json, constants.FilenameChangeLog)
+	file, err := os.OpenFile(fileLog, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(value, '\n'))
+	return err
+}
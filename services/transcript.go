@@ -0,0 +1,131 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+	"github.com/rizalarfiyan/skillshare-downloader/logger"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/subtitle"
+	"github.com/rizalarfiyan/skillshare-downloader/utils"
+)
+
+// createTranscript write the transcript of every downloaded subtitle next to
+// it and the transcript of the whole class in the class directory.
+func (s *skillshare) createTranscript(ss models.SkillshareClass) error {
+	if s.conf.Transcript == "" {
+		return nil
+	}
+
+	var class strings.Builder
+	fmt.Fprintf(&class, "# %s\n\n", ss.Title)
+	if ss.Teacher != "" {
+		fmt.Fprintf(&class, "By %s\n\n", ss.Teacher)
+	}
+
+	count := 0
+	for idx, val := range ss.Videos {
+		if !s.isSelected(val.ID) {
+			continue
+		}
+
+		sub, ok := s.transcriptSubtitle(val)
+		if !ok {
+			logger.Debugf("[%d] No subtitle for the transcript", val.ID)
+			continue
+		}
+
+		subPath, err := s.layout().subtitlePath(ss, idx, sub)
+		if err != nil {
+			return err
+		}
+
+		cues, err := subtitle.ParseVTTFile(subPath)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				logger.Warningf("[%d] Failed read the subtitle: %s", val.ID, err.Error())
+			}
+			continue
+		}
+
+		paragraphs := subtitle.Paragraphs(cues)
+		lessonPath := s.layout().transcriptPath(subPath, s.conf.Transcript)
+		logger.Debugf("[%d] Write transcript to file: %s", val.ID, lessonPath)
+		err = os.WriteFile(lessonPath, []byte(s.lessonTranscript(val.Title, paragraphs)), 0o644)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(&class, "## %d. %s\n\n", idx+1, val.Title)
+		writeParagraphs(&class, paragraphs, s.conf.IsTranscriptTime)
+		count++
+	}
+
+	if count == 0 {
+		logger.Info("No subtitle for the transcript")
+		return nil
+	}
+
+	classPath := path.Join(s.dir.base, constants.FilenameTranscript)
+	logger.Debugf("Write class transcript to file: %s", classPath)
+	err := utils.CreateDir(path.Dir(classPath))
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(classPath, []byte(class.String()), 0o644)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Create transcript of %d lessons done", count)
+	return nil
+}
+
+// transcriptSubtitle returns the downloaded subtitle of the lesson, the
+// configured language first when more languages are downloaded.
+func (s *skillshare) transcriptSubtitle(video models.SkillshareVideo) (models.SkillshareVideoSubtitle, bool) {
+	var found *models.SkillshareVideoSubtitle
+	for idx, sub := range video.Subtitles {
+		if !s.langs[strings.ToLower(sub.Lang)] {
+			continue
+		}
+
+		if strings.EqualFold(sub.Lang, s.conf.Lang) {
+			return sub, true
+		}
+
+		if found == nil {
+			found = &video.Subtitles[idx]
+		}
+	}
+
+	if found == nil {
+		return models.SkillshareVideoSubtitle{}, false
+	}
+	return *found, true
+}
+
+func (s *skillshare) lessonTranscript(title string, paragraphs []subtitle.Paragraph) string {
+	var sb strings.Builder
+	if s.conf.Transcript == constants.TranscriptMarkdown {
+		fmt.Fprintf(&sb, "# %s\n\n", title)
+	}
+
+	writeParagraphs(&sb, paragraphs, s.conf.IsTranscriptTime)
+	return sb.String()
+}
+
+func writeParagraphs(sb *strings.Builder, paragraphs []subtitle.Paragraph, isTime bool) {
+	for _, paragraph := range paragraphs {
+		if isTime {
+			fmt.Fprintf(sb, "[%s] ", subtitle.FormatTimestamp(paragraph.Start))
+		}
+		sb.WriteString(paragraph.Text)
+		sb.WriteString("\n\n")
+	}
+}
@@ -0,0 +1,7 @@
+package services
+
+import "github.com/rizalarfiyan/skillshare-downloader/models"
+
+type Verify interface {
+	Run(conf models.Config, ids []int) ([]models.VerifyClass, error)
+}
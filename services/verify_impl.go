@@ -0,0 +1,217 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rizalarfiyan/skillshare-downloader/logger"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/mp4"
+	"github.com/rizalarfiyan/skillshare-downloader/utils"
+)
+
+type verify struct {
+	conf models.AppConfig
+}
+
+func NewVerify() Verify {
+	return &verify{}
+}
+
+// Run audit the downloaded classes, every class in the download root is
+// checked when ids is empty.
+func (v *verify) Run(conf models.Config, ids []int) ([]models.VerifyClass, error) {
+	logger.Debug("Load the config")
+	if err := v.conf.LoadLocal(conf); err != nil {
+		return nil, err
+	}
+
+	logger.Debugf("Search class directory: %s", v.conf.Dir)
+	dirs, err := findClassDirs(v.conf.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	dirs, err = filterClassDirs(dirs, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []models.VerifyClass
+	for _, dir := range dirs {
+		lc, err := loadLocalClass(v.conf, dir.Path)
+		if err != nil {
+			result = append(result, models.VerifyClass{
+				ID:  dir.ID,
+				Dir: relativePath(v.conf.Dir, dir.Path),
+				Issues: []models.VerifyIssue{{
+					Kind:   models.IssueMissingData,
+					Path:   relativePath(v.conf.Dir, dir.Path),
+					Detail: err.Error(),
+				}},
+			})
+			continue
+		}
+
+		report, err := v.verifyClass(lc)
+		if err != nil {
+			return nil, err
+		}
+
+		logger.Debugf("[%d] Verified with %d issues", report.ID, len(report.Issues))
+		result = append(result, report)
+	}
+
+	return result, nil
+}
+
+func filterClassDirs(dirs []classDir, ids []int) ([]classDir, error) {
+	if len(ids) == 0 {
+		return dirs, nil
+	}
+
+	var filtered []classDir
+	for _, id := range ids {
+		found := false
+		for _, dir := range dirs {
+			if dir.ID == id {
+				filtered = append(filtered, dir)
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("class %d is not downloaded", id)
+		}
+	}
+
+	return filtered, nil
+}
+
+func (v *verify) verifyClass(lc *localClass) (models.VerifyClass, error) {
+	report := models.VerifyClass{
+		ID:      lc.class.ID,
+		Title:   lc.class.Title,
+		Dir:     relativePath(v.conf.Dir, lc.layout.base),
+		Lessons: len(lc.class.Videos),
+		Issues:  []models.VerifyIssue{},
+	}
+
+	var videoPaths []string
+	for idx, video := range lc.class.Videos {
+		issue := models.VerifyIssue{
+			Index:    idx + 1,
+			LessonID: video.ID,
+			Lesson:   video.Title,
+		}
+
+		if lc.videos[idx] == nil {
+			issue.Kind = models.IssueMissingData
+			issue.Path = relativePath(v.conf.Dir, lc.layout.videoDataPath(idx, video))
+			issue.Detail = "lesson data is not cached"
+			report.Issues = append(report.Issues, issue)
+			videoPaths = append(videoPaths, "")
+			continue
+		}
+
+		source, ok := lc.layout.source(video)
+		if !ok {
+			videoPaths = append(videoPaths, "")
+			continue
+		}
+
+		filePath, err := lc.layout.videoPath(lc.class, idx, source)
+		if err != nil {
+			return report, err
+		}
+		videoPaths = append(videoPaths, filePath)
+
+		for _, found := range v.verifyVideo(filePath, source, lc.layout.isAudioOnly(video)) {
+			found.Index, found.LessonID, found.Lesson = issue.Index, issue.LessonID, issue.Lesson
+			report.Issues = append(report.Issues, found)
+		}
+
+		sub, ok := v.findSubtitle(video.Subtitles)
+		if !ok {
+			continue
+		}
+
+		subPath, err := lc.layout.subtitlePath(lc.class, idx, sub)
+		if err != nil {
+			return report, err
+		}
+
+		if _, ok := statFile(subPath, nil); !ok {
+			issue.Kind = models.IssueMissingSubtitle
+			issue.Path = relativePath(v.conf.Dir, subPath)
+			issue.Detail = fmt.Sprintf("subtitle %s is not downloaded", sub.Lang)
+			report.Issues = append(report.Issues, issue)
+		}
+	}
+
+	if !utils.IsExistPath(lc.layout.video) {
+		return report, nil
+	}
+
+	parts, err := utils.SearchFiles(lc.layout.video, "*.part*")
+	if err != nil {
+		return report, err
+	}
+
+	for _, part := range parts {
+		issue := models.VerifyIssue{
+			Kind:   models.IssuePartFile,
+			Path:   relativePath(v.conf.Dir, part),
+			Detail: "leftover of an interrupted download",
+		}
+
+		for idx, filePath := range videoPaths {
+			if filePath != "" && strings.HasPrefix(part, filePath) {
+				issue.Index = idx + 1
+				issue.LessonID = lc.class.Videos[idx].ID
+				issue.Lesson = lc.class.Videos[idx].Title
+				break
+			}
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+
+	return report, nil
+}
+
+func (v *verify) verifyVideo(filePath string, source models.SkillshareVideoSource, isAudioOnly bool) []models.VerifyIssue {
+	issue := models.VerifyIssue{
+		Path: relativePath(v.conf.Dir, filePath),
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		issue.Kind = models.IssueMissingVideo
+		issue.Detail = fmt.Sprintf("video %dp is not downloaded", source.Height)
+		return []models.VerifyIssue{issue}
+	}
+
+	var issues []models.VerifyIssue
+	// the size of the source is the whole video, not the extracted audio
+	if !isAudioOnly && source.Size > 0 && info.Size() != int64(source.Size) {
+		issue.Kind = models.IssueSizeMismatch
+		issue.Detail = fmt.Sprintf("expected %d bytes, got %d bytes", source.Size, info.Size())
+		issues = append(issues, issue)
+	}
+
+	if isAudioOnly || strings.EqualFold(source.Container, "MP4") {
+		if err := mp4.ValidateFile(filePath); err != nil {
+			issue.Kind = models.IssueInvalidVideo
+			issue.Detail = err.Error()
+			issues = append(issues, issue)
+		}
+	}
+
+	return issues
+}
+
+// findSubtitle returns the subtitle of configured language.
+func (v *verify) findSubtitle(subtitles []models.SkillshareVideoSubtitle) (models.SkillshareVideoSubtitle, bool) {
+	return matchSubtitle(subtitles, v.conf.Lang)
+}
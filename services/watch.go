@@ -0,0 +1,7 @@
+package services
+
+import "github.com/rizalarfiyan/skillshare-downloader/models"
+
+type Watch interface {
+	Run(conf models.Config, opts models.WatchOptions) error
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+	"github.com/rizalarfiyan/skillshare-downloader/logger"
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+)
+
+type watch struct {
+	ctx   context.Context
+	conf  models.AppConfig
+	state models.WatchState
+	opts  []SkillshareOption
+}
+
+// NewWatch passes the options to every sync of the classes.
+func NewWatch(ctx context.Context, opts ...SkillshareOption) Watch {
+	return &watch{
+		ctx:  ctx,
+		opts: opts,
+	}
+}
+
+// Run sync the classes of the list on every interval until the context is
+// done, the schedule and failures are kept in the state file.
+func (w *watch) Run(conf models.Config, opts models.WatchOptions) error {
+	logger.Debug("Load the config")
+	if err := w.conf.LoadLocal(conf); err != nil {
+		return err
+	}
+
+	if opts.Interval <= 0 {
+		opts.Interval = constants.DefaultWatchInterval
+	}
+
+	if err := w.loadState(); err != nil {
+		return err
+	}
+
+	// once run the cycle now, only the backoff of the rate limit is waited
+	next := time.Now()
+	if !opts.Once {
+		next = w.state.LastCycle.Add(opts.Interval)
+	}
+
+	for {
+		if w.state.BackoffUntil.After(next) {
+			next = w.state.BackoffUntil
+		}
+
+		if !w.sleepUntil(next) {
+			logger.Info("Watch is stopped")
+			return nil
+		}
+
+		if err := w.cycle(conf, opts); err != nil {
+			return err
+		}
+
+		if err := w.saveState(); err != nil {
+			return err
+		}
+
+		if opts.Once {
+			return nil
+		}
+
+		next = w.state.LastCycle.Add(opts.Interval)
+	}
+}
+
+func (w *watch) sleepUntil(next time.Time) bool {
+	wait := time.Until(next)
+	if wait <= 0 {
+		return w.ctx.Err() == nil
+	}
+
+	logger.Infof("Next cycle at %s", next.Format(constants.DefaultTimestampFormat))
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-w.ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (w *watch) cycle(conf models.Config, opts models.WatchOptions) error {
+	ids, err := readClassesFile(opts.ClassesFile)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	w.state.Cycle++
+	logger.Infof("Start cycle %d with %d classes", w.state.Cycle, len(ids))
+
+	summary := models.WatchSummary{}
+	for _, id := range ids {
+		if w.ctx.Err() != nil {
+			break
+		}
+
+		class, ok := w.state.Classes[id]
+		if !ok {
+			class = &models.WatchClass{}
+			w.state.Classes[id] = class
+		}
+
+		if class.NextRun.After(time.Now()) {
+			logger.Infof("[%d] Skip class, retry after %s", id, class.NextRun.Format(constants.DefaultTimestampFormat))
+			summary.Skipped++
+			continue
+		}
+
+		conf.UrlOrId = strconv.Itoa(id)
+		syncLog, err := w.syncClass(conf)
+		if w.ctx.Err() != nil {
+			logger.Infof("[%d] Sync is interrupted", id)
+			break
+		}
+
+		if err != nil {
+			class.LastError = err.Error()
+			summary.Failed++
+			if errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrRateLimited) {
+				w.state.Failures++
+				w.state.BackoffUntil = time.Now().Add(backoff(w.state.Failures))
+				logger.Warningf("[%d] %s, stop the cycle until %s", id, err.Error(), w.state.BackoffUntil.Format(constants.DefaultTimestampFormat))
+				break
+			}
+
+			class.Failures++
+			class.NextRun = time.Now().Add(backoff(class.Failures))
+			logger.Warningf("[%d] Failed sync class: %s", id, err.Error())
+			continue
+		}
+
+		w.state.Failures = 0
+		class.Failures = 0
+		class.LastError = ""
+		class.LastSync = time.Now()
+		class.NextRun = time.Time{}
+		if syncLog != nil {
+			summary.Add(syncLog)
+		} else {
+			summary.Synced++
+		}
+	}
+
+	w.state.LastCycle = time.Now()
+	logger.Infof("Cycle %d done in %s: %d synced, %d skipped, %d failed, %d added, %d updated, %d removed, %d moved",
+		w.state.Cycle,
+		time.Since(start).Round(time.Second),
+		summary.Synced,
+		summary.Skipped,
+		summary.Failed,
+		summary.Added,
+		summary.Updated,
+		summary.Removed,
+		summary.Moved,
+	)
+	return nil
+}
+
+// syncClass download the whole class when it is not downloaded yet.
+func (w *watch) syncClass(conf models.Config) (*models.SyncLog, error) {
+	syncLog, err := NewSkillshare(w.ctx, w.opts...).Sync(conf)
+	if !errors.Is(err, ErrNotDownloaded) {
+		return syncLog, err
+	}
+
+	logger.Infof("[%s] Class is not downloaded, download all lessons", conf.UrlOrId)
+	return nil, NewSkillshare(w.ctx, w.opts...).Run(conf)
+}
+
+func backoff(failures int) time.Duration {
+	delay := constants.WatchBackoff
+	for i := 1; i < failures && delay < constants.WatchMaxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > constants.WatchMaxBackoff {
+		return constants.WatchMaxBackoff
+	}
+	return delay
+}
+
+// readClassesFile returns the class of every line, empty lines and lines
+// starting with # are skipped.
+func readClassesFile(pathfile string) ([]int, error) {
+	file, err := os.Open(pathfile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var ids []int
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		id, err := models.ParseClassID(line)
+		if err != nil {
+			logger.Warningf("Skip %s in %s: %s", line, pathfile, err.Error())
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, scanner.Err()
+}
+
+func (w *watch) statePath() string {
+	return path.Join(w.conf.Dir, constants.FilenameWatchState)
+}
+
+func (w *watch) loadState() error {
+	w.state = models.WatchState{
+		Classes: make(map[int]*models.WatchClass),
+	}
+
+	data, err := os.ReadFile(w.statePath())
+	if errors.Is(err, os.ErrNotExist) {
+		logger.Debug("No watch state, start a new one")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, &w.state); err != nil {
+		return err
+	}
+
+	if w.state.Classes == nil {
+		w.state.Classes = make(map[int]*models.WatchClass)
+	}
+	logger.Debugf("Load watch state at cycle %d", w.state.Cycle)
+	return nil
+}
+
+func (w *watch) saveState() error {
+	data, err := json.MarshalIndent(w.state, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(w.conf.Dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	tmpFile := w.statePath() + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile, w.statePath())
+}
@@ -0,0 +1,115 @@
+package subtitle
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+)
+
+// DualCue is a caption with the text of both languages.
+type DualCue struct {
+	Start     time.Duration
+	End       time.Duration
+	Primary   string
+	Secondary string
+}
+
+// Merge align the secondary cues to the primary cues. The secondary track is
+// shifted by the median drift of the close cues first, then every secondary
+// cue is attached to the primary cue it overlaps the most and take its
+// timing. A secondary cue without any overlap keep its own timing.
+func Merge(primary, secondary []Cue) []DualCue {
+	secondary = Shift(secondary, Drift(primary, secondary))
+
+	cues := make([]DualCue, len(primary))
+	for idx, cue := range primary {
+		cues[idx] = DualCue{Start: cue.Start, End: cue.End, Primary: cue.Text}
+	}
+
+	texts := make([][]string, len(primary))
+	for _, cue := range secondary {
+		best, bestOverlap := -1, time.Duration(0)
+		for idx, target := range primary {
+			if overlap := overlapTime(cue, target); overlap > bestOverlap {
+				best, bestOverlap = idx, overlap
+			}
+		}
+
+		if best < 0 {
+			cues = append(cues, DualCue{Start: cue.Start, End: cue.End, Secondary: cue.Text})
+			continue
+		}
+		texts[best] = append(texts[best], cue.Text)
+	}
+
+	for idx, text := range texts {
+		cues[idx].Secondary = strings.Join(text, "\n")
+	}
+
+	sort.SliceStable(cues, func(i, j int) bool {
+		return cues[i].Start < cues[j].Start
+	})
+	return cues
+}
+
+// Drift returns the median difference of the start of the secondary cues
+// with the closest primary cue, the cues further than the max drift are
+// not the same caption.
+func Drift(primary, secondary []Cue) time.Duration {
+	var diffs []time.Duration
+	for _, cue := range secondary {
+		var closest time.Duration
+		found := false
+		for _, target := range primary {
+			diff := target.Start - cue.Start
+			if diff.Abs() > constants.DualSubsMaxDrift {
+				continue
+			}
+
+			if !found || diff.Abs() < closest.Abs() {
+				closest, found = diff, true
+			}
+		}
+
+		if found {
+			diffs = append(diffs, closest)
+		}
+	}
+
+	if len(diffs) == 0 {
+		return 0
+	}
+
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i] < diffs[j] })
+	return diffs[len(diffs)/2]
+}
+
+// Shift returns the cues moved by offset, the time is never negative.
+func Shift(cues []Cue, offset time.Duration) []Cue {
+	shifted := make([]Cue, len(cues))
+	for idx, cue := range cues {
+		cue.Start += offset
+		cue.End += offset
+		if cue.Start < 0 {
+			cue.Start = 0
+		}
+		if cue.End < cue.Start {
+			cue.End = cue.Start
+		}
+		shifted[idx] = cue
+	}
+	return shifted
+}
+
+func overlapTime(a, b Cue) time.Duration {
+	start, end := a.Start, a.End
+	if b.Start > start {
+		start = b.Start
+	}
+	if b.End < end {
+		end = b.End
+	}
+	return end - start
+}
@@ -0,0 +1,107 @@
+package subtitle
+
+import (
+	"strings"
+	"time"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+)
+
+// Paragraph is the text of the cues said without a long pause.
+type Paragraph struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// Paragraphs merge the cues into paragraphs. The rolling captions repeat
+// the previous line at the start of the next cue, the repeated words are
+// removed. A paragraph ends at a pause or at the end of a sentence when it
+// is long enough.
+func Paragraphs(cues []Cue) []Paragraph {
+	var paragraphs []Paragraph
+	var words []string
+	var current Paragraph
+	flush := func() {
+		if len(words) > 0 {
+			current.Text = strings.Join(words, " ")
+			paragraphs = append(paragraphs, current)
+		}
+		words = nil
+	}
+
+	var last []string
+	var lastEnd time.Duration
+	for _, cue := range cues {
+		cueWords := strings.Fields(cue.Text)
+		cueWords = cueWords[overlap(last, cueWords):]
+		last = append(last, cueWords...)
+		if len(last) > constants.TranscriptOverlapWords {
+			last = last[len(last)-constants.TranscriptOverlapWords:]
+		}
+
+		if len(cueWords) == 0 {
+			if cue.End > current.End {
+				current.End = cue.End
+			}
+			continue
+		}
+
+		isPause := len(words) > 0 && cue.Start-lastEnd >= constants.TranscriptParagraphGap
+		isLong := len(words) >= constants.TranscriptParagraphWords && isSentenceEnd(words[len(words)-1])
+		if isPause || isLong {
+			flush()
+		}
+
+		if len(words) == 0 {
+			current = Paragraph{Start: cue.Start}
+		}
+		words = append(words, cueWords...)
+		if cue.End > current.End {
+			current.End = cue.End
+		}
+		lastEnd = cue.End
+	}
+	flush()
+
+	return paragraphs
+}
+
+// overlap returns the number of words at the start of next which repeat the
+// end of prev. One repeated word is kept unless it is the whole cue, it is
+// more likely said twice than rolled.
+func overlap(prev, next []string) int {
+	size := len(prev)
+	if len(next) < size {
+		size = len(next)
+	}
+
+	for ; size > 0; size-- {
+		if size == 1 && len(next) > 1 {
+			return 0
+		}
+
+		if equalWords(prev[len(prev)-size:], next[:size]) {
+			return size
+		}
+	}
+	return 0
+}
+
+func equalWords(a, b []string) bool {
+	for idx := range a {
+		if !strings.EqualFold(trimPunct(a[idx]), trimPunct(b[idx])) {
+			return false
+		}
+	}
+	return true
+}
+
+func trimPunct(word string) string {
+	return strings.Trim(word, ".,!?;:\"'")
+}
+
+func isSentenceEnd(word string) bool {
+	word = strings.TrimRight(word, "\"')")
+	return strings.HasSuffix(word, ".") || strings.HasSuffix(word, "?") || strings.HasSuffix(word, "!")
+}
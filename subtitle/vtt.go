@@ -0,0 +1,161 @@
+package subtitle
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoCue = errors.New("subtitle has no cue")
+
+	regexTag = regexp.MustCompile(`<[^>]*>`)
+)
+
+// Cue is a caption shown between Start and End, Text keep the line breaks
+// but not the styling tags.
+type Cue struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// ParseVTT read the cues of a WebVTT, the NOTE, STYLE and REGION blocks are
+// skipped. The SRT timestamps with a comma are accepted too.
+func ParseVTT(r io.Reader) ([]Cue, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var cues []Cue
+	var block []string
+	flush := func() error {
+		defer func() { block = block[:0] }()
+		for idx, line := range block {
+			if !strings.Contains(line, "-->") {
+				continue
+			}
+
+			cue, err := parseTiming(line)
+			if err != nil {
+				return err
+			}
+
+			cue.Text = cleanText(block[idx+1:])
+			if cue.Text != "" {
+				cues = append(cues, cue)
+			}
+			return nil
+		}
+		return nil
+	}
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) != "" {
+			block = append(block, strings.TrimPrefix(line, "\ufeff"))
+			continue
+		}
+
+		if err := flush(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	if len(cues) == 0 {
+		return nil, ErrNoCue
+	}
+	return cues, nil
+}
+
+// ParseVTTFile is ParseVTT of the file.
+func ParseVTTFile(filePath string) ([]Cue, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	cues, err := ParseVTT(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	return cues, nil
+}
+
+func parseTiming(line string) (Cue, error) {
+	parts := strings.SplitN(line, "-->", 2)
+	start, err := ParseTimestamp(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return Cue{}, err
+	}
+
+	// the settings of the cue follow the end time
+	fields := strings.Fields(parts[1])
+	if len(fields) == 0 {
+		return Cue{}, fmt.Errorf("invalid cue timing %q", line)
+	}
+
+	end, err := ParseTimestamp(fields[0])
+	if err != nil {
+		return Cue{}, err
+	}
+
+	return Cue{Start: start, End: end}, nil
+}
+
+// ParseTimestamp parse hh:mm:ss.ttt or mm:ss.ttt.
+func ParseTimestamp(str string) (time.Duration, error) {
+	str = strings.Replace(str, ",", ".", 1)
+	parts := strings.Split(str, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timestamp %q", str)
+	}
+
+	seconds, err := strconv.ParseFloat(parts[len(parts)-1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timestamp %q", str)
+	}
+
+	total := time.Duration(seconds * float64(time.Second))
+	for idx, unit := range []time.Duration{time.Minute, time.Hour}[:len(parts)-1] {
+		value, err := strconv.Atoi(parts[len(parts)-2-idx])
+		if err != nil {
+			return 0, fmt.Errorf("invalid timestamp %q", str)
+		}
+		total += time.Duration(value) * unit
+	}
+
+	return total.Round(time.Millisecond), nil
+}
+
+// FormatTimestamp returns hh:mm:ss, the timestamp of the transcripts.
+func FormatTimestamp(d time.Duration) string {
+	d = d.Truncate(time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+}
+
+func cleanText(lines []string) string {
+	var cleaned []string
+	for _, line := range lines {
+		line = strings.TrimSpace(html.UnescapeString(regexTag.ReplaceAllString(line, "")))
+		if line != "" {
+			cleaned = append(cleaned, line)
+		}
+	}
+	return strings.Join(cleaned, "\n")
+}
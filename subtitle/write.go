@@ -0,0 +1,73 @@
+package subtitle
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+)
+
+// WriteDualVTT write the cues as WebVTT, the secondary text use the
+// secondary class which is styled smaller.
+func WriteDualVTT(w io.Writer, cues []DualCue) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "WEBVTT\n\nSTYLE\n::cue(.secondary) { font-size: %d%%; }\n\n", constants.DualSubsSecondaryScale)
+	for idx, cue := range cues {
+		fmt.Fprintf(bw, "%d\n%s --> %s\n", idx+1, formatVTT(cue.Start), formatVTT(cue.End))
+		if cue.Primary != "" {
+			fmt.Fprintf(bw, "%s\n", escapeVTT(cue.Primary))
+		}
+		if cue.Secondary != "" {
+			fmt.Fprintf(bw, "<c.secondary>%s</c>\n", escapeVTT(cue.Secondary))
+		}
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
+
+// WriteDualASS write the cues as Advanced SubStation Alpha, the primary
+// text is above the smaller secondary text in the same event.
+func WriteDualASS(w io.Writer, title string, cues []DualCue) error {
+	primarySize := constants.DualSubsFontSize
+	secondarySize := primarySize * constants.DualSubsSecondaryScale / 100
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "[Script Info]\nTitle: %s\nScriptType: v4.00+\nPlayResX: 1920\nPlayResY: 1080\nWrapStyle: 0\n\n", title)
+	bw.WriteString("[V4+ Styles]\n")
+	bw.WriteString("Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding\n")
+	fmt.Fprintf(bw, "Style: Primary,Arial,%d,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,3,0,2,60,60,50,1\n", primarySize)
+	fmt.Fprintf(bw, "Style: Secondary,Arial,%d,&H00D0D0D0,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,2,60,60,50,1\n\n", secondarySize)
+	bw.WriteString("[Events]\nFormat: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n")
+	for _, cue := range cues {
+		var lines []string
+		if cue.Primary != "" {
+			lines = append(lines, escapeASS(cue.Primary))
+		}
+		if cue.Secondary != "" {
+			lines = append(lines, `{\rSecondary}`+escapeASS(cue.Secondary))
+		}
+		fmt.Fprintf(bw, "Dialogue: 0,%s,%s,Primary,,0,0,0,,%s\n", formatASS(cue.Start), formatASS(cue.End), strings.Join(lines, `\N`))
+	}
+	return bw.Flush()
+}
+
+func formatVTT(d time.Duration) string {
+	return fmt.Sprintf("%s.%03d", FormatTimestamp(d), d.Milliseconds()%1000)
+}
+
+func formatASS(d time.Duration) string {
+	d = d.Truncate(10 * time.Millisecond)
+	return fmt.Sprintf("%d:%02d:%02d.%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60, d.Milliseconds()%1000/10)
+}
+
+func escapeVTT(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+func escapeASS(text string) string {
+	text = strings.NewReplacer("{", "(", "}", ")").Replace(text)
+	return strings.ReplaceAll(text, "\n", `\N`)
+}
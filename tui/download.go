@@ -0,0 +1,187 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/utils"
+)
+
+const (
+	lessonWaiting = iota
+	lessonRunning
+	lessonDone
+	lessonSkipped
+	lessonFailed
+)
+
+type lessonState struct {
+	index    int
+	title    string
+	status   int
+	progress models.Progress
+	err      string
+}
+
+// download is the second screen with the progress of every selected lesson.
+type download struct {
+	class      models.SkillshareClass
+	lessons    []*lessonState
+	subtitle   models.Event
+	cancelling bool
+	result     string
+}
+
+func newDownload(ss models.SkillshareClass, selection *models.Selection) *download {
+	d := &download{class: ss}
+	for idx, video := range ss.Videos {
+		if utils.Contains(selection.Lessons, video.ID) {
+			d.lessons = append(d.lessons, &lessonState{index: idx + 1, title: video.Title})
+		}
+	}
+	return d
+}
+
+func (d *download) lesson(index int) *lessonState {
+	for _, lesson := range d.lessons {
+		if lesson.index == index {
+			return lesson
+		}
+	}
+	return nil
+}
+
+// update returns true when the event change more than the byte progress.
+func (d *download) update(event models.Event) bool {
+	switch {
+	case event.Type == models.EventProgress && event.Progress != nil:
+		lesson := d.lesson(event.Progress.Index)
+		if lesson == nil {
+			return false
+		}
+
+		lesson.progress = *event.Progress
+		if event.Progress.Done {
+			lesson.status = lessonDone
+			return true
+		}
+
+		changed := lesson.status != lessonRunning
+		lesson.status = lessonRunning
+		return changed
+	case event.Type == models.EventStep && event.Stage == models.StageVideo:
+		lesson := d.lesson(event.Current)
+		if lesson != nil && lesson.status == lessonWaiting {
+			lesson.status = lessonSkipped
+		}
+		return true
+	case event.Type == models.EventError && event.Stage == models.StageVideo:
+		for _, lesson := range d.lessons {
+			if lesson.status == lessonRunning {
+				lesson.status = lessonFailed
+				lesson.err = event.Error
+			}
+		}
+		return true
+	case event.Stage == models.StageSubtitle:
+		d.subtitle = event
+		return true
+	}
+	return false
+}
+
+func (d *download) finish(err error) {
+	switch {
+	case err == nil:
+		d.result = "Download is done"
+	case d.cancelling:
+		d.result = "Download is cancelled"
+	default:
+		d.result = fmt.Sprintf("Download is failed: %s", err.Error())
+	}
+}
+
+func (d *download) render(width, height int) []string {
+	lines := []string{
+		fmt.Sprintf(" \x1b[1mDownloading %s\x1b[0m", d.class.Title),
+		"",
+	}
+
+	titleWidth := width - 60
+	if titleWidth < 10 {
+		titleWidth = 10
+	}
+
+	// keep the running lesson visible when the list is longer than the screen
+	rows := height - 6
+	if rows < 1 {
+		rows = 1
+	}
+	offset := 0
+	for idx, lesson := range d.lessons {
+		if lesson.status == lessonRunning && idx >= rows {
+			offset = idx - rows + 1
+		}
+	}
+
+	for idx, lesson := range d.lessons {
+		if idx < offset || idx >= offset+rows {
+			continue
+		}
+
+		title := pad(lesson.title, titleWidth)
+		switch lesson.status {
+		case lessonWaiting:
+			lines = append(lines, fmt.Sprintf("   %03d. %s", lesson.index, title))
+		case lessonRunning:
+			lines = append(lines, fmt.Sprintf(" \x1b[36m▸\x1b[0m %03d. %s %s", lesson.index, title, progressBar(lesson.progress)))
+		case lessonDone:
+			lines = append(lines, fmt.Sprintf(" \x1b[32m✓\x1b[0m %03d. %s %s", lesson.index, title, formatSize(lesson.progress.TotalBytes)))
+		case lessonSkipped:
+			lines = append(lines, fmt.Sprintf(" \x1b[32m✓\x1b[0m %03d. %s already downloaded", lesson.index, title))
+		case lessonFailed:
+			lines = append(lines, fmt.Sprintf(" \x1b[31m✗\x1b[0m %03d. %s %s", lesson.index, title, lesson.err))
+		}
+	}
+
+	lines = append(lines, "")
+	if d.subtitle.Stage != "" {
+		status := fmt.Sprintf(" Subtitles %d downloaded", d.subtitle.Current)
+		if d.subtitle.Type == models.EventDone {
+			status += ", done"
+		}
+		lines = append(lines, status)
+	} else {
+		lines = append(lines, "")
+	}
+
+	switch {
+	case d.result != "":
+		lines = append(lines, fmt.Sprintf(" \x1b[1m%s\x1b[0m, press any key to exit", d.result))
+	case d.cancelling:
+		lines = append(lines, " Cancelling the download...")
+	default:
+		lines = append(lines, " \x1b[2mq cancel\x1b[0m")
+	}
+	return lines
+}
+
+func progressBar(progress models.Progress) string {
+	const width = 20
+	if progress.TotalBytes <= 0 {
+		return "[" + strings.Repeat("-", width) + "]"
+	}
+
+	done := int(progress.Bytes * width / progress.TotalBytes)
+	if done > width {
+		done = width
+	}
+
+	return fmt.Sprintf("[%s%s] %3d%% %9s/s",
+		strings.Repeat("=", done),
+		strings.Repeat("-", width-done),
+		progress.Bytes*100/progress.TotalBytes,
+		utils.FormatBytes(progress.Speed),
+	)
+}
@@ -0,0 +1,60 @@
+package tui
+
+type key int
+
+const (
+	keyUp key = iota + 1
+	keyDown
+	keyLeft
+	keyRight
+	keySpace
+	keyEnter
+	keyTab
+	keyAll
+	keyQuit
+)
+
+// parseKeys read the keys of the raw terminal input, the arrows are sent as
+// escape sequences and vim keys are accepted too.
+func parseKeys(input []byte) []key {
+	var keys []key
+	for i := 0; i < len(input); i++ {
+		switch input[i] {
+		case 0x1b:
+			if i+2 < len(input) && (input[i+1] == '[' || input[i+1] == 'O') {
+				switch input[i+2] {
+				case 'A':
+					keys = append(keys, keyUp)
+				case 'B':
+					keys = append(keys, keyDown)
+				case 'C':
+					keys = append(keys, keyRight)
+				case 'D':
+					keys = append(keys, keyLeft)
+				}
+				i += 2
+				continue
+			}
+			keys = append(keys, keyQuit)
+		case 'k':
+			keys = append(keys, keyUp)
+		case 'j':
+			keys = append(keys, keyDown)
+		case 'h':
+			keys = append(keys, keyLeft)
+		case 'l':
+			keys = append(keys, keyRight)
+		case ' ', 'x':
+			keys = append(keys, keySpace)
+		case '\r', '\n':
+			keys = append(keys, keyEnter)
+		case '\t':
+			keys = append(keys, keyTab)
+		case 'a':
+			keys = append(keys, keyAll)
+		case 'q', 0x03:
+			keys = append(keys, keyQuit)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,293 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"github.com/rizalarfiyan/skillshare-downloader/utils"
+)
+
+const (
+	focusLessons = iota
+	focusQuality
+	focusLanguages
+	focusCount
+)
+
+const (
+	actionNone = iota
+	actionStart
+	actionQuit
+)
+
+type language struct {
+	lang     string
+	label    string
+	selected bool
+}
+
+// picker is the first screen, every lesson is selected by default.
+type picker struct {
+	class     models.SkillshareClass
+	selected  []bool
+	qualities []int
+	quality   int
+	languages []language
+	focus     int
+	cursor    int
+	offset    int
+	langIdx   int
+}
+
+func newPicker(ss models.SkillshareClass, opts Options) *picker {
+	p := &picker{
+		class:     ss,
+		selected:  make([]bool, len(ss.Videos)),
+		qualities: []int{0},
+	}
+
+	heights := make(map[int]bool)
+	langs := make(map[string]string)
+	for idx, video := range ss.Videos {
+		p.selected[idx] = true
+		for _, source := range video.Sources {
+			heights[source.Height] = true
+		}
+		for _, sub := range video.Subtitles {
+			langs[sub.Lang] = sub.Label
+		}
+	}
+
+	for height := range heights {
+		if height > 0 {
+			p.qualities = append(p.qualities, height)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(p.qualities[1:])))
+
+	if opts.Quality > 0 {
+		for idx := 1; idx < len(p.qualities); idx++ {
+			if p.qualities[idx] <= opts.Quality {
+				p.quality = idx
+				break
+			}
+		}
+	}
+
+	for lang, label := range langs {
+		p.languages = append(p.languages, language{lang: lang, label: label})
+	}
+	sort.Slice(p.languages, func(i, j int) bool {
+		return p.languages[i].lang < p.languages[j].lang
+	})
+	p.selectLanguage(opts.Language)
+
+	return p
+}
+
+// selectLanguage select the same language of the config, or the first one
+// with the same prefix like en for en-US.
+func (p *picker) selectLanguage(lang string) {
+	for idx := range p.languages {
+		if strings.EqualFold(p.languages[idx].lang, lang) {
+			p.languages[idx].selected = true
+			return
+		}
+	}
+
+	prefix := strings.ToLower(strings.Split(lang, "-")[0])
+	for idx := range p.languages {
+		if strings.ToLower(strings.Split(p.languages[idx].lang, "-")[0]) == prefix {
+			p.languages[idx].selected = true
+			return
+		}
+	}
+}
+
+func (p *picker) handle(k key) int {
+	switch k {
+	case keyQuit:
+		return actionQuit
+	case keyEnter:
+		if p.count() > 0 {
+			return actionStart
+		}
+	case keyTab:
+		p.focus = (p.focus + 1) % focusCount
+	case keyAll:
+		all := p.count() != len(p.selected)
+		for idx := range p.selected {
+			p.selected[idx] = all
+		}
+	case keyUp:
+		if p.focus == focusLessons && p.cursor > 0 {
+			p.cursor--
+		}
+	case keyDown:
+		if p.focus == focusLessons && p.cursor < len(p.selected)-1 {
+			p.cursor++
+		}
+	case keyLeft, keyRight:
+		step := 1
+		if k == keyLeft {
+			step = -1
+		}
+		switch p.focus {
+		case focusLessons, focusQuality:
+			p.quality = (p.quality + step + len(p.qualities)) % len(p.qualities)
+		case focusLanguages:
+			if len(p.languages) > 0 {
+				p.langIdx = (p.langIdx + step + len(p.languages)) % len(p.languages)
+			}
+		}
+	case keySpace:
+		switch p.focus {
+		case focusLessons:
+			if len(p.selected) > 0 {
+				p.selected[p.cursor] = !p.selected[p.cursor]
+			}
+		case focusLanguages:
+			if len(p.languages) > 0 {
+				p.languages[p.langIdx].selected = !p.languages[p.langIdx].selected
+			}
+		}
+	}
+	return actionNone
+}
+
+func (p *picker) count() int {
+	count := 0
+	for _, selected := range p.selected {
+		if selected {
+			count++
+		}
+	}
+	return count
+}
+
+func (p *picker) size(video models.SkillshareVideo) int64 {
+	source, ok := video.SelectSource(p.qualities[p.quality])
+	if !ok {
+		return 0
+	}
+	return int64(source.Size)
+}
+
+func (p *picker) selection() *models.Selection {
+	selection := &models.Selection{
+		Quality:   p.qualities[p.quality],
+		Languages: []string{},
+	}
+
+	for idx, video := range p.class.Videos {
+		if p.selected[idx] {
+			selection.Lessons = append(selection.Lessons, video.ID)
+		}
+	}
+
+	for _, lang := range p.languages {
+		if lang.selected {
+			selection.Languages = append(selection.Languages, lang.lang)
+		}
+	}
+	return selection
+}
+
+func (p *picker) render(width, height int) []string {
+	lines := []string{
+		fmt.Sprintf(" \x1b[1m%s\x1b[0m", p.class.Title),
+		fmt.Sprintf(" by %s, %d lessons", p.class.Teacher, len(p.class.Videos)),
+		"",
+	}
+
+	rows := height - 11
+	if rows < 1 {
+		rows = 1
+	}
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	}
+	if p.cursor >= p.offset+rows {
+		p.offset = p.cursor - rows + 1
+	}
+
+	titleWidth := width - 35
+	if titleWidth < 10 {
+		titleWidth = 10
+	}
+
+	var total int64
+	for idx, video := range p.class.Videos {
+		size := p.size(video)
+		if p.selected[idx] {
+			total += size
+		}
+
+		if idx < p.offset || idx >= p.offset+rows {
+			continue
+		}
+
+		check := " "
+		if p.selected[idx] {
+			check = "x"
+		}
+
+		line := fmt.Sprintf(" [%s] %03d. %s %8s %11s", check, idx+1, pad(video.Title, titleWidth), video.VideoDuration, formatSize(size))
+		if idx == p.cursor && p.focus == focusLessons {
+			line = "\x1b[7m" + line + "\x1b[0m"
+		}
+		lines = append(lines, line)
+	}
+
+	quality := fmt.Sprintf("‹ %s ›", formatQuality(p.qualities[p.quality]))
+	if p.focus == focusQuality {
+		quality = "\x1b[7m" + quality + "\x1b[0m"
+	}
+
+	var langs []string
+	for idx, lang := range p.languages {
+		check := " "
+		if lang.selected {
+			check = "x"
+		}
+
+		item := fmt.Sprintf("[%s] %s", check, lang.label)
+		if idx == p.langIdx && p.focus == focusLanguages {
+			item = "\x1b[7m" + item + "\x1b[0m"
+		}
+		langs = append(langs, item)
+	}
+	if len(langs) == 0 {
+		langs = append(langs, "no subtitle")
+	}
+
+	help := "↑/↓ move  space select  a all  ←/→ quality"
+	if p.focus == focusLanguages {
+		help = "←/→ move  space select"
+	}
+
+	return append(lines,
+		"",
+		fmt.Sprintf(" Quality    %s", quality),
+		fmt.Sprintf(" Languages  %s", strings.Join(langs, "  ")),
+		"",
+		fmt.Sprintf(" Selected %d of %d lessons, estimated %s", p.count(), len(p.selected), formatSize(total)),
+		fmt.Sprintf(" \x1b[2m%s  tab next  enter download  q quit\x1b[0m", help),
+	)
+}
+
+func formatQuality(height int) string {
+	if height == 0 {
+		return "best"
+	}
+	return fmt.Sprintf("%dp", height)
+}
+
+func formatSize(size int64) string {
+	if size <= 0 {
+		return "-"
+	}
+	return utils.FormatBytes(size)
+}
@@ -0,0 +1,270 @@
+// Package tui is the full screen terminal interface of --interactive, it
+// picks the lessons, quality and languages and shows the download progress.
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rizalarfiyan/skillshare-downloader/models"
+	"golang.org/x/term"
+)
+
+const renderInterval = 100 * time.Millisecond
+
+var ErrNotTerminal = errors.New("interactive mode needs a terminal")
+
+type Options struct {
+	Quality  int
+	Language string
+	Color    bool
+}
+
+// colorPattern match the foreground colors, the bold and reverse video are
+// kept without colors to show the cursor.
+var colorPattern = regexp.MustCompile(`\x1b\[3[0-9]m`)
+
+type UI struct {
+	in     *os.File
+	out    *os.File
+	opts   Options
+	cancel context.CancelFunc
+
+	mu         sync.Mutex
+	state      *term.State
+	keys       chan key
+	exit       chan struct{}
+	exitOnce   sync.Once
+	finished   bool
+	lastRender time.Time
+	download   *download
+}
+
+// New returns the interface on stdin and stdout, cancel is called when the
+// user quit during the download.
+func New(cancel context.CancelFunc, opts Options) *UI {
+	return &UI{
+		in:     os.Stdin,
+		out:    os.Stdout,
+		opts:   opts,
+		cancel: cancel,
+		exit:   make(chan struct{}),
+	}
+}
+
+// Select show the lessons of the class until the user start the download,
+// it returns nil when the user quit.
+func (u *UI) Select(ss models.SkillshareClass) (*models.Selection, error) {
+	if err := u.open(); err != nil {
+		return nil, err
+	}
+
+	p := newPicker(ss, u.opts)
+	u.draw(p.render(u.size()))
+	for k := range u.keys {
+		switch p.handle(k) {
+		case actionQuit:
+			return nil, nil
+		case actionStart:
+			selection := p.selection()
+			u.mu.Lock()
+			u.download = newDownload(ss, selection)
+			u.mu.Unlock()
+			u.draw(u.download.render(u.size()))
+			go u.watchKeys()
+			return selection, nil
+		}
+		u.draw(p.render(u.size()))
+	}
+
+	return nil, nil
+}
+
+// Report update the download progress, it implements reporter.Reporter.
+func (u *UI) Report(event models.Event) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.download == nil {
+		return
+	}
+
+	changed := u.download.update(event)
+	if !changed && time.Since(u.lastRender) < renderInterval {
+		return
+	}
+
+	u.lastRender = time.Now()
+	u.drawLocked(u.download.render(u.size()))
+}
+
+// Finish show the result of the download and wait for a key before the
+// terminal is restored.
+func (u *UI) Finish(err error) {
+	u.mu.Lock()
+	if u.state == nil || u.download == nil {
+		u.mu.Unlock()
+		u.Close()
+		return
+	}
+
+	u.finished = true
+	u.download.finish(err)
+	u.drawLocked(u.download.render(u.size()))
+	u.mu.Unlock()
+
+	<-u.exit
+	u.Close()
+}
+
+// Close restore the terminal, it is safe to call more than once.
+func (u *UI) Close() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.state == nil {
+		return
+	}
+
+	fmt.Fprint(u.out, "\x1b[?25h\x1b[?1049l")
+	_ = term.Restore(int(u.in.Fd()), u.state)
+	u.state = nil
+}
+
+// IsSupported returns false when stdin or stdout is not a terminal.
+func IsSupported() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+func (u *UI) open() error {
+	if !term.IsTerminal(int(u.in.Fd())) || !term.IsTerminal(int(u.out.Fd())) {
+		return ErrNotTerminal
+	}
+
+	state, err := term.MakeRaw(int(u.in.Fd()))
+	if err != nil {
+		return err
+	}
+
+	u.mu.Lock()
+	u.state = state
+	u.mu.Unlock()
+
+	fmt.Fprint(u.out, "\x1b[?1049h\x1b[?25l")
+	u.keys = make(chan key)
+	go u.readKeys()
+	return nil
+}
+
+func (u *UI) readKeys() {
+	buf := make([]byte, 64)
+	for {
+		n, err := u.in.Read(buf)
+		if err != nil {
+			close(u.keys)
+			return
+		}
+
+		for _, k := range parseKeys(buf[:n]) {
+			u.keys <- k
+		}
+	}
+}
+
+// watchKeys cancel the download on quit, after the download is finished any
+// key exit the interface.
+func (u *UI) watchKeys() {
+	defer u.exitOnce.Do(func() { close(u.exit) })
+	for k := range u.keys {
+		u.mu.Lock()
+		finished := u.finished
+		if !finished && k == keyQuit {
+			u.download.cancelling = true
+			u.drawLocked(u.download.render(u.size()))
+		}
+		u.mu.Unlock()
+
+		if finished {
+			return
+		}
+
+		if k == keyQuit {
+			u.cancel()
+		}
+	}
+}
+
+func (u *UI) size() (int, int) {
+	width, height, err := term.GetSize(int(u.out.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return 80, 24
+	}
+	return width, height
+}
+
+func (u *UI) draw(lines []string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.drawLocked(lines)
+}
+
+func (u *UI) drawLocked(lines []string) {
+	if u.state == nil {
+		return
+	}
+
+	width, _ := u.size()
+	var sb strings.Builder
+	sb.WriteString("\x1b[H\x1b[2J")
+	for idx, line := range lines {
+		if idx > 0 {
+			sb.WriteString("\r\n")
+		}
+		if !u.opts.Color {
+			line = colorPattern.ReplaceAllString(line, "")
+		}
+		sb.WriteString(truncate(line, width))
+	}
+	fmt.Fprint(u.out, sb.String())
+}
+
+// truncate cut the line to the width of the terminal, the escape sequences
+// are not counted.
+func truncate(line string, width int) string {
+	var sb strings.Builder
+	count := 0
+	escape := false
+	for _, r := range line {
+		switch {
+		case r == '\x1b':
+			escape = true
+		case escape:
+			if r == 'm' {
+				escape = false
+			}
+		default:
+			if count >= width {
+				continue
+			}
+			count++
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+func pad(text string, width int) string {
+	count := utf8.RuneCountInString(text)
+	if count > width {
+		runes := []rune(text)
+		return string(runes[:width-1]) + "…"
+	}
+	return text + strings.Repeat(" ", width-count)
+}
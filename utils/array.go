@@ -8,3 +8,12 @@ func Filter[T any](data []T, test func(T) bool) (res []T) {
 	}
 	return
 }
+
+func Contains[T comparable](data []T, value T) bool {
+	for _, val := range data {
+		if val == value {
+			return true
+		}
+	}
+	return false
+}
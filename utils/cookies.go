@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 func GetCookieTxt(pathfile string) (string, error) {
@@ -32,3 +34,89 @@ func CleanCookies(cookie string) string {
 	cookie = re.ReplaceAllString(cookie, "\n")
 	return strings.ReplaceAll(cookie, "\n", " ")
 }
+
+func RedactCookies(cookie string) string {
+	if cookie == "" {
+		return ""
+	}
+
+	var redacted []string
+	for _, item := range strings.Split(cookie, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(item, "=")
+		redacted = append(redacted, fmt.Sprintf("%s=***", name))
+	}
+	return strings.Join(redacted, "; ")
+}
+
+type Cookie struct {
+	Domain     string
+	Name       string
+	Value      string
+	Expires    time.Time
+	IsHttpOnly bool
+}
+
+// MatchDomain returns true when the cookie is sent to the domain or its
+// subdomains.
+func (c Cookie) MatchDomain(domain string) bool {
+	cookieDomain := strings.ToLower(strings.TrimPrefix(c.Domain, "."))
+	return cookieDomain == domain || strings.HasSuffix(cookieDomain, "."+domain)
+}
+
+// FilterCookies returns the cookies of the domain.
+func FilterCookies(cookies []Cookie, domain string) []Cookie {
+	var filtered []Cookie
+	for _, cookie := range cookies {
+		if cookie.MatchDomain(domain) {
+			filtered = append(filtered, cookie)
+		}
+	}
+	return filtered
+}
+
+// ParseNetscapeCookies parse the cookies.txt format exported by the browser,
+// it returns false when the text is not in that format.
+func ParseNetscapeCookies(text string) ([]Cookie, bool) {
+	var cookies []Cookie
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+		isHttpOnly := strings.HasPrefix(line, "#HttpOnly_")
+		line = strings.TrimPrefix(line, "#HttpOnly_")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			return nil, false
+		}
+
+		cookie := Cookie{
+			Domain:     fields[0],
+			Name:       fields[5],
+			Value:      fields[6],
+			IsHttpOnly: isHttpOnly,
+		}
+
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err == nil && expires > 0 {
+			cookie.Expires = time.Unix(expires, 0)
+		}
+
+		cookies = append(cookies, cookie)
+	}
+
+	return cookies, len(cookies) > 0
+}
+
+func JoinCookies(cookies []Cookie) string {
+	var items []string
+	for _, cookie := range cookies {
+		items = append(items, fmt.Sprintf("%s=%s", cookie.Name, cookie.Value))
+	}
+	return strings.Join(items, "; ")
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gosimple/slug"
+	"github.com/gosimple/unidecode"
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+)
+
+var (
+	// illegal on windows, the slash and the control characters are illegal
+	// everywhere else too
+	regexIllegalName = regexp.MustCompile(`[<>:"/\\|?*\x00-\x1f\x7f]+`)
+	regexSpace       = regexp.MustCompile(`\s+`)
+	reservedNames    = map[string]bool{
+		"CON": true, "PRN": true, "AUX": true, "NUL": true,
+		"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+		"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+	}
+)
+
+// SafeName keeps the unicode letters of the name and strips only the
+// characters which are illegal on the common filesystems.
+func SafeName(filename string) string {
+	str := regexIllegalName.ReplaceAllString(DecodeAscii(filename), "")
+	str = strings.TrimSpace(regexSpace.ReplaceAllString(str, " "))
+	return CleanName(str, constants.MaxNameBytes)
+}
+
+// SafeFilename strip the illegal characters of a rendered filename and cut
+// it to the limit of the filesystems, the extension is kept.
+func SafeFilename(filename string) string {
+	return CleanName(regexIllegalName.ReplaceAllString(filename, ""), constants.MaxFilenameBytes)
+}
+
+// SafeNameOr returns the transliteration of the name when the safe name is
+// empty, and the fallback when both are empty.
+func SafeNameOr(filename, fallback string) string {
+	if name := SafeName(filename); name != "" {
+		return name
+	}
+
+	if name := SafeName(unidecode.Unidecode(filename)); name != "" {
+		return name
+	}
+	return SafeName(fallback)
+}
+
+// ToSnakeCase slugify the latin names like before, the names in other
+// scripts keep their letters instead of a lossy transliteration.
+func ToSnakeCase(str string) string {
+	str = DecodeAscii(str)
+	if isLatin(str) {
+		return strings.ReplaceAll(slug.MakeLang(str, "en"), "-", "_")
+	}
+
+	var sb strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(str) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) {
+			sb.WriteRune(r)
+			underscore = false
+			continue
+		}
+
+		if sb.Len() > 0 && !underscore {
+			sb.WriteRune('_')
+			underscore = true
+		}
+	}
+
+	name := CleanName(strings.Trim(sb.String(), "_"), constants.MaxNameBytes)
+	if name == "" {
+		return strings.ReplaceAll(slug.MakeLang(str, "en"), "-", "_")
+	}
+	return name
+}
+
+// CleanName fix the name for windows, the trailing dots and spaces are
+// removed, a reserved device name get an underscore and the name is cut to
+// max bytes without breaking a character or the extension.
+func CleanName(name string, max int) string {
+	name = strings.TrimRight(name, ". ")
+	if name == "" {
+		return ""
+	}
+
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	if len(ext) >= max {
+		ext = ""
+		base = name
+	}
+
+	device := strings.ToUpper(strings.SplitN(base, ".", 2)[0])
+	if reservedNames[strings.TrimSpace(device)] {
+		base += "_"
+	}
+
+	for len(base)+len(ext) > max {
+		_, size := utf8.DecodeLastRuneInString(base)
+		base = base[:len(base)-size]
+	}
+
+	return strings.TrimRight(base, ". ") + ext
+}
+
+func isLatin(str string) bool {
+	for _, r := range str {
+		if unicode.IsLetter(r) && !unicode.Is(unicode.Latin, r) {
+			return false
+		}
+	}
+	return true
+}
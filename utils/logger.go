@@ -12,6 +12,7 @@ import (
 type Logrus struct {
 	TimestampFormat string
 	LogFormat       string
+	NoColor         bool
 }
 
 func (f *Logrus) Format(entry *logrus.Entry) ([]byte, error) {
@@ -42,8 +43,10 @@ func (f *Logrus) Format(entry *logrus.Entry) ([]byte, error) {
 	output = strings.Replace(output, "%time%", entry.Time.Format(timestampFormat), 1)
 	output = strings.Replace(output, "%msg%", entry.Message, 1)
 	level := strings.ToUpper(entry.Level.String())
-	colored := fmt.Sprintf("\x1b[%dm%s\x1b[0m", levelColor, level)
-	output = strings.Replace(output, "%lvl%", colored, 1)
+	if !f.NoColor {
+		level = fmt.Sprintf("\x1b[%dm%s\x1b[0m", levelColor, level)
+	}
+	output = strings.Replace(output, "%lvl%", level, 1)
 
 	for k, val := range entry.Data {
 		switch v := val.(type) {
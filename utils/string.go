@@ -6,18 +6,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
-
-	"github.com/gosimple/slug"
 )
 
-func SafeName(filename string) string {
-	str := DecodeAscii(filename)
-	reSafe := regexp.MustCompile(`(?m)[^A-Za-z0-9-_ ]+`)
-	reSpace := regexp.MustCompile(`\s+`)
-	safeStr := reSafe.ReplaceAllString(str, "")
-	return strings.TrimSpace(reSpace.ReplaceAllString(safeStr, " "))
-}
-
 func DecodeAscii(str string) string {
 	r := regexp.MustCompile(`\\x[0-9A-Fa-f]{2}`)
 	matches := r.FindAllString(str, -1)
@@ -32,11 +22,6 @@ func DecodeAscii(str string) string {
 	return str
 }
 
-func ToSnakeCase(str string) string {
-	tempStr := slug.MakeLang(str, "en")
-	return strings.ReplaceAll(tempStr, "-", "_")
-}
-
 func MatchExtenstion(filename string, defaultExtension string) string {
 	extension := defaultExtension
 	ext := filepath.Ext(filename)
@@ -49,3 +34,42 @@ func MatchExtenstion(filename string, defaultExtension string) string {
 	}
 	return extension
 }
+
+// ParseBytes parse the size with optional binary unit, e.g. 512K, 1.5M or 2GB.
+func ParseBytes(value string) (int64, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	value = strings.TrimSuffix(strings.TrimSuffix(value, "B"), "I")
+
+	multiplier := int64(1)
+	if value != "" {
+		if idx := strings.IndexByte("KMGT", value[len(value)-1]); idx >= 0 {
+			multiplier = int64(1) << (10 * (idx + 1))
+			value = value[:len(value)-1]
+		}
+	}
+
+	size, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if size < 0 {
+		return 0, fmt.Errorf("negative size %s", value)
+	}
+
+	return int64(size * float64(multiplier)), nil
+}
+
+func FormatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
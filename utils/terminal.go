@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"os"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+	"golang.org/x/term"
+)
+
+// IsTerminal returns false when the file is redirected or piped.
+func IsTerminal(file *os.File) bool {
+	return term.IsTerminal(int(file.Fd()))
+}
+
+// IsNoColor returns true when NO_COLOR is set to any value, see
+// https://no-color.org.
+func IsNoColor() bool {
+	return os.Getenv(constants.EnvNoColor) != ""
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignedURLExpiry read the expiry of signed url from the query parameter,
+// it supports cloudfront, s3 and akamai token style of signature.
+func SignedURLExpiry(rawURL string) (time.Time, bool) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	query := parsed.Query()
+	for _, key := range []string{"Expires", "expires", "exp", "Expiry"} {
+		if value := query.Get(key); value != "" {
+			if expiry, ok := parseUnix(value); ok {
+				return expiry, true
+			}
+		}
+	}
+
+	if date, expires := query.Get("X-Amz-Date"), query.Get("X-Amz-Expires"); date != "" && expires != "" {
+		signedAt, err := time.Parse("20060102T150405Z", date)
+		seconds, errSeconds := strconv.Atoi(expires)
+		if err == nil && errSeconds == nil {
+			return signedAt.Add(time.Duration(seconds) * time.Second), true
+		}
+	}
+
+	for _, key := range []string{"hdnts", "__token__", "hdnea"} {
+		if value := query.Get(key); value != "" {
+			if expiry, ok := parseTokenExpiry(value); ok {
+				return expiry, true
+			}
+		}
+	}
+
+	if policy := query.Get("Policy"); policy != "" {
+		if expiry, ok := parseCloudfrontPolicy(policy); ok {
+			return expiry, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// IsSignedURLExpired returns false when the expiry of url is unknown.
+func IsSignedURLExpired(rawURL string, margin time.Duration) bool {
+	expiry, ok := SignedURLExpiry(rawURL)
+	if !ok {
+		return false
+	}
+
+	return time.Now().Add(margin).After(expiry)
+}
+
+func parseUnix(value string) (time.Time, bool) {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds <= 0 {
+		return time.Time{}, false
+	}
+
+	return time.Unix(seconds, 0), true
+}
+
+func parseTokenExpiry(token string) (time.Time, bool) {
+	re := regexp.MustCompile(`(?:^|[~&])exp=(\d+)`)
+	match := re.FindStringSubmatch(token)
+	if len(match) < 2 {
+		return time.Time{}, false
+	}
+
+	return parseUnix(match[1])
+}
+
+func parseCloudfrontPolicy(policy string) (time.Time, bool) {
+	replacer := strings.NewReplacer("-", "+", "_", "=", "~", "/")
+	data, err := base64.StdEncoding.DecodeString(replacer.Replace(policy))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	dest := struct {
+		Statement []struct {
+			Condition struct {
+				DateLessThan struct {
+					EpochTime int64 `json:"AWS:EpochTime"`
+				} `json:"DateLessThan"`
+			} `json:"Condition"`
+		} `json:"Statement"`
+	}{}
+
+	err = json.Unmarshal(data, &dest)
+	if err != nil || len(dest.Statement) == 0 {
+		return time.Time{}, false
+	}
+
+	return parseUnix(strconv.FormatInt(dest.Statement[0].Condition.DateLessThan.EpochTime, 10))
+}
@@ -0,0 +1,54 @@
+package vault
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+	"golang.org/x/term"
+)
+
+// Passphrase reads the passphrase from the environment variable, otherwise
+// prompt it in the terminal. With confirm, the prompt is asked twice.
+func Passphrase(confirm bool) (string, error) {
+	if passphrase, ok := os.LookupEnv(constants.EnvVaultPassphrase); ok {
+		return passphrase, nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("vault passphrase is required, set %s or run in a terminal", constants.EnvVaultPassphrase)
+	}
+
+	passphrase, err := prompt(fd, "Vault passphrase: ")
+	if err != nil {
+		return "", err
+	}
+
+	if !confirm {
+		return passphrase, nil
+	}
+
+	again, err := prompt(fd, "Confirm passphrase: ")
+	if err != nil {
+		return "", err
+	}
+
+	if passphrase != again {
+		return "", errors.New("passphrase does not match")
+	}
+
+	return passphrase, nil
+}
+
+func prompt(fd int, label string) (string, error) {
+	fmt.Fprint(os.Stderr, label)
+	value, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+
+	return string(value), nil
+}
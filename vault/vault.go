@@ -0,0 +1,218 @@
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/rizalarfiyan/skillshare-downloader/constants"
+	"github.com/rizalarfiyan/skillshare-downloader/utils"
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	fileVersion = 1
+	saltSize    = 16
+	keySize     = 32
+)
+
+var (
+	ErrAccountNotFound   = errors.New("account not found in vault")
+	ErrInvalidPassphrase = errors.New("invalid vault passphrase")
+)
+
+type Vault struct {
+	path string
+	file vaultFile
+}
+
+type vaultFile struct {
+	Version  int                `json:"version"`
+	Accounts map[string]account `json:"accounts"`
+}
+
+type account struct {
+	KDF       kdf       `json:"kdf"`
+	Salt      []byte    `json:"salt"`
+	Nonce     []byte    `json:"nonce"`
+	Data      []byte    `json:"data"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// kdf holds the argon2id parameters, kept per account so they can be tuned
+// later without breaking the existing vault.
+type kdf struct {
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+}
+
+var defaultKDF = kdf{
+	Time:    3,
+	Memory:  64 * 1024,
+	Threads: 4,
+}
+
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, constants.ConfigDirName, constants.VaultFileName)
+}
+
+// Open reads the vault file, a missing file is an empty vault.
+func Open(pathfile string) (*Vault, error) {
+	if pathfile == "" {
+		pathfile = DefaultPath()
+	}
+
+	if pathfile == "" {
+		return nil, errors.New("vault path is not set")
+	}
+
+	v := &Vault{
+		path: pathfile,
+		file: vaultFile{
+			Version:  fileVersion,
+			Accounts: make(map[string]account),
+		},
+	}
+
+	if !utils.IsExistPath(pathfile) {
+		return v, nil
+	}
+
+	data, err := os.ReadFile(pathfile)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &v.file)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vault file %s: %w", pathfile, err)
+	}
+
+	if v.file.Version != fileVersion {
+		return nil, fmt.Errorf("unsupported vault version %d", v.file.Version)
+	}
+
+	if v.file.Accounts == nil {
+		v.file.Accounts = make(map[string]account)
+	}
+
+	return v, nil
+}
+
+func (v *Vault) Path() string {
+	return v.path
+}
+
+func (v *Vault) Accounts() []string {
+	names := make([]string, 0, len(v.file.Accounts))
+	for name := range v.file.Accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (v *Vault) Store(name, passphrase, cookies string) error {
+	if name == "" {
+		return errors.New("account name is required")
+	}
+
+	if passphrase == "" {
+		return errors.New("passphrase is required")
+	}
+
+	acc := account{
+		KDF:       defaultKDF,
+		Salt:      make([]byte, saltSize),
+		UpdatedAt: time.Now(),
+	}
+
+	if _, err := rand.Read(acc.Salt); err != nil {
+		return err
+	}
+
+	aead, err := acc.aead(passphrase)
+	if err != nil {
+		return err
+	}
+
+	acc.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(acc.Nonce); err != nil {
+		return err
+	}
+
+	acc.Data = aead.Seal(nil, acc.Nonce, []byte(cookies), []byte(name))
+	v.file.Accounts[name] = acc
+	return v.save()
+}
+
+func (v *Vault) Load(name, passphrase string) (string, error) {
+	acc, ok := v.file.Accounts[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrAccountNotFound, name)
+	}
+
+	aead, err := acc.aead(passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := aead.Open(nil, acc.Nonce, acc.Data, []byte(name))
+	if err != nil {
+		return "", ErrInvalidPassphrase
+	}
+
+	return string(data), nil
+}
+
+func (v *Vault) Remove(name string) error {
+	if _, ok := v.file.Accounts[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrAccountNotFound, name)
+	}
+
+	delete(v.file.Accounts, name)
+	return v.save()
+}
+
+func (v *Vault) save() error {
+	data, err := json.MarshalIndent(v.file, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(v.path), 0o700)
+	if err != nil {
+		return err
+	}
+
+	tmpFile := v.path + ".tmp"
+	err = os.WriteFile(tmpFile, data, 0o600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile, v.path)
+}
+
+func (acc account) aead(passphrase string) (cipher.AEAD, error) {
+	key := argon2.IDKey([]byte(passphrase), acc.Salt, acc.KDF.Time, acc.KDF.Memory, acc.KDF.Threads, keySize)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}